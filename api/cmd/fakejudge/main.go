@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"learning_go/internal/database"
+	"learning_go/internal/fakejudge"
+	model "learning_go/internal/models"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	addr := flag.String("addr", ":3001", "address to listen on")
+	behavior := flag.String("behavior", fakejudge.Echo, "default behavior: echo, compile-error, runtime-error, status or malformed")
+	delay := flag.Duration("delay", 0, "default delay before answering")
+	caseDelay := flag.Duration("case-delay", 0, "default delay before each test case's result")
+	status := flag.Int("status", http.StatusInternalServerError, "HTTP status returned by the status behavior")
+	problemsFile := flag.String("problems", "", "JSON file with the problems whose expected outputs are echoed")
+	fromDB := flag.Bool("db", false, "load the problems whose expected outputs are echoed from MongoDB (MONGO_URI)")
+	flag.Parse()
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	problems, err := loadProblems(*problemsFile, *fromDB)
+	if err != nil {
+		log.Fatalf("Failed to load problems: %v", err)
+	}
+
+	defaults := fakejudge.Script{
+		Behavior:   *behavior,
+		Delay:      *delay,
+		CaseDelay:  *caseDelay,
+		Line:       1,
+		Column:     1,
+		StatusCode: *status,
+	}
+	switch *behavior {
+	case fakejudge.CompileError:
+		defaults.Message = "syntax error"
+	case fakejudge.RuntimeError:
+		defaults.Message = "segmentation fault"
+	case fakejudge.Echo, fakejudge.Status, fakejudge.Malformed:
+	default:
+		log.Fatalf("Unknown behavior %q", *behavior)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /performTestCases", fakejudge.NewServer(defaults, problems))
+
+	log.Printf("Fake judge listening on %s (default behavior %s, %d problems)", *addr, *behavior, len(problems))
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// loadProblems reads the problems from file, from MongoDB, or both
+func loadProblems(file string, fromDB bool) ([]*model.Problem, error) {
+	var problems []*model.Problem
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &problems); err != nil {
+			return nil, err
+		}
+	}
+
+	if fromDB {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		db, err := database.NewMongoDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Disconnect(context.Background())
+
+		stored, err := model.NewProblemService(db.Database).GetAllProblems(ctx)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, stored...)
+	}
+
+	return problems, nil
+}
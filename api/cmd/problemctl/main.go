@@ -0,0 +1,321 @@
+// Command problemctl manages problems as packages kept in version control.
+//
+//	problemctl validate PACKAGE...               check packages without touching the database
+//	problemctl import [-fill] PACKAGE...         create or update problems by slug
+//	problemctl export [-o DIR] [-zip] [SLUG...]  write stored problems as packages (all by default)
+//	problemctl diff PACKAGE...                   show what importing the packages would change
+//
+// A package is a directory or a .zip archive; see internal/problempkg for
+// its layout. Commands that use the database read MONGO_URI and
+// MONGO_DATABASE, and import -fill reads the judge configuration like the
+// server does.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"learning_go/internal/database"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"learning_go/internal/problempkg"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errFailed reports that at least one package failed; the details have
+// already been printed
+var errFailed = errors.New("failed")
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage:
+  problemctl validate PACKAGE...
+  problemctl import [-fill] PACKAGE...
+  problemctl export [-o DIR] [-zip] [SLUG...]
+  problemctl diff PACKAGE...`)
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	var err error
+	switch command {
+	case "validate":
+		err = validate(args)
+	case "import":
+		err = importPackages(args)
+	case "export":
+		err = export(args)
+	case "diff":
+		err = diff(args)
+	default:
+		usage()
+	}
+
+	if err == errFailed {
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", command, err)
+	}
+}
+
+// openDatabase connects to the database and gives every stored problem a
+// slug, so that problems created before slugs existed match their packages
+func openDatabase(ctx context.Context) (*database.MongoDB, error) {
+	db, err := database.NewMongoDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := model.NewProblemService(db.Database).EnsureIndexes(ctx); err != nil {
+		db.Disconnect(ctx)
+		return nil, err
+	}
+	if _, err := model.BackfillProblemSlugs(ctx, db.Database); err != nil {
+		db.Disconnect(ctx)
+		return nil, err
+	}
+	return db, nil
+}
+
+// load reads and validates a package
+func load(pkgPath string) (*model.Problem, error) {
+	problem, err := problempkg.Load(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := problem.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", pkgPath, err)
+	}
+	return problem, nil
+}
+
+func validate(args []string) error {
+	if len(args) == 0 {
+		usage()
+	}
+
+	failed := false
+	for _, pkgPath := range args {
+		problem, err := load(pkgPath)
+		if err != nil {
+			fmt.Printf("FAIL %v\n", err)
+			failed = true
+			continue
+		}
+		fmt.Printf("ok   %s (%s, %d test cases)\n", pkgPath, problem.Slug, len(problem.TestCases))
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+func importPackages(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	fill := flags.Bool("fill", false, "generate missing expected outputs with the reference solution")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		usage()
+	}
+
+	ctx := context.Background()
+	db, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Disconnect(ctx)
+
+	var runner judge.Runner
+	if *fill {
+		if runner, err = judge.NewFromEnv(); err != nil {
+			return err
+		}
+	}
+
+	problemService := model.NewProblemService(db.Database)
+	failed := false
+	for _, pkgPath := range flags.Args() {
+		problem, err := load(pkgPath)
+		if err == nil && *fill {
+			err = fillOutputs(ctx, runner, problem)
+		}
+		if err != nil {
+			fmt.Printf("FAIL %v\n", err)
+			failed = true
+			continue
+		}
+
+		created, err := problemService.UpsertProblemBySlug(ctx, problem)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", pkgPath, err)
+			failed = true
+			continue
+		}
+		action := "updated"
+		if created {
+			action = "created"
+		}
+		fmt.Printf("%s %s (%s)\n", action, problem.Slug, problem.ID.Hex())
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+// fillOutputs runs the reference solution to generate the expected outputs
+// missing from a package, refusing packages it disagrees with
+func fillOutputs(ctx context.Context, runner judge.Runner, problem *model.Problem) error {
+	if problem.ReferenceSolution == "" {
+		return nil
+	}
+
+	report, err := judge.CheckReference(ctx, runner, problem)
+	if err != nil {
+		return fmt.Errorf("%s: %w", problem.Slug, err)
+	}
+	if report.Mismatched > 0 || report.Errors > 0 {
+		return fmt.Errorf("%s: reference solution disagrees with %d test cases and fails %d",
+			problem.Slug, report.Mismatched, report.Errors)
+	}
+	report.Apply(problem, false)
+	return problem.Validate()
+}
+
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := flags.String("o", ".", "directory to write the packages to")
+	asZip := flags.Bool("zip", false, "write .zip archives instead of directories")
+	flags.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	db, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Disconnect(context.Background())
+
+	problemService := model.NewProblemService(db.Database)
+	var problems []*model.Problem
+	if flags.NArg() == 0 {
+		if problems, err = problemService.GetAllProblems(ctx); err != nil {
+			return err
+		}
+	}
+	for _, slug := range flags.Args() {
+		problem, err := problemService.GetProblemBySlug(ctx, slug)
+		if err == mongo.ErrNoDocuments {
+			return fmt.Errorf("no problem with slug %q", slug)
+		}
+		if err != nil {
+			return err
+		}
+		problems = append(problems, problem)
+	}
+
+	failed := false
+	for _, problem := range problems {
+		// Slugs are backfilled unless another problem already took the slug
+		// derived from the title; exporting under it would overwrite that one
+		if problem.Slug == "" {
+			fmt.Printf("FAIL %s: problem has no slug\n", problem.ID.Hex())
+			failed = true
+			continue
+		}
+		if err := problem.NormalizeTestCases(); err != nil {
+			fmt.Printf("FAIL %s: %v\n", problem.Slug, err)
+			failed = true
+			continue
+		}
+
+		dest := filepath.Join(*dir, problem.Slug)
+		if *asZip {
+			dest += ".zip"
+		}
+		if err := problempkg.Write(dest, problem); err != nil {
+			fmt.Printf("FAIL %s: %v\n", problem.Slug, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("exported %s to %s\n", problem.Slug, dest)
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+func diff(args []string) error {
+	if len(args) == 0 {
+		usage()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	db, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Disconnect(context.Background())
+
+	problemService := model.NewProblemService(db.Database)
+	changed := false
+	for _, pkgPath := range args {
+		problem, err := load(pkgPath)
+		if err != nil {
+			fmt.Printf("FAIL %v\n", err)
+			changed = true
+			continue
+		}
+
+		stored, err := problemService.GetProblemBySlug(ctx, problem.Slug)
+		if err == mongo.ErrNoDocuments {
+			fmt.Printf("%s: not in the database\n", problem.Slug)
+			changed = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := stored.NormalizeTestCases(); err != nil {
+			fmt.Printf("FAIL %s: stored problem: %v\n", problem.Slug, err)
+			changed = true
+			continue
+		}
+
+		differences, err := problempkg.Diff(stored, problem)
+		if err != nil {
+			return err
+		}
+		for _, difference := range differences {
+			fmt.Printf("%s: %s\n", problem.Slug, difference)
+		}
+		if len(differences) > 0 {
+			changed = true
+		}
+	}
+
+	// Like diff(1), exit with status 1 when something differs
+	if changed {
+		return errFailed
+	}
+	return nil
+}
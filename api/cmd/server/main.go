@@ -3,8 +3,12 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"learning_go/internal/cache"
 	"learning_go/internal/database"
+	"learning_go/internal/judge"
 	model "learning_go/internal/models"
+	"learning_go/internal/queue"
+	"learning_go/internal/rejudge"
 	"log"
 	"net/http"
 	"os"
@@ -46,8 +50,67 @@ func main() {
 	log.Println("Testing database operations...")
 	testDatabaseOperations(ctx, userService)
 
+	// Index the problems collection for the problem list search and filters,
+	// and name the problems created before slugs existed
+	if err := model.NewProblemService(db.Database).EnsureIndexes(ctx); err != nil {
+		log.Printf("Failed to create problem indexes: %v", err)
+	}
+	if backfilled, err := model.BackfillProblemSlugs(ctx, db.Database); err != nil {
+		log.Printf("Failed to backfill problem slugs: %v", err)
+	} else if backfilled > 0 {
+		log.Printf("Backfilled the slugs of %d problems", backfilled)
+	}
+
+	// Show the first test case of problems stored before samples existed
+	if marked, err := model.MarkLegacySamples(ctx, db.Database); err != nil {
+		log.Printf("Failed to mark legacy sample test cases: %v", err)
+	} else if marked > 0 {
+		log.Printf("Marked the first test case of %d problems as a sample", marked)
+	}
+
+	// Index the submissions collection and backfill it from the request logs once
+	if err := model.NewSubmissionService(db.Database).EnsureIndexes(ctx); err != nil {
+		log.Printf("Failed to create submission indexes: %v", err)
+	}
+	if migrated, err := model.MigrateSubmissionsFromLogs(ctx, db.Database); err != nil {
+		log.Printf("Failed to migrate submissions from logs: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d submissions from logs", migrated)
+	}
+
+	// Initialize judge backends (JUDGE_BACKEND / JUDGE_LANGUAGES / JUDGE_URL / JUDGE_URLS)
+	runner, err := judge.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure judge: %v", err)
+	}
+	judge.StartHealthChecks(ctx, runner)
+
+	// Start the submission workers (SUBMISSION_WORKERS / SUBMISSION_QUEUE_SIZE)
+	submissions := queue.NewFromEnv(db.Database, runner)
+	submissions.Start(ctx)
+
+	// Initialize compile cache (COMPILE_CACHE_SIZE / COMPILE_CACHE_SHARED)
+	compileCache, err := cache.NewFromEnv(ctx, db.Database)
+	if err != nil {
+		log.Fatalf("Failed to configure compile cache: %v", err)
+	}
+
+	// Diagnostics are requested on every pause in typing, so they get a
+	// small cache of their own
+	diagnosticsCache := cache.NewCompileCache(200, time.Hour, nil)
+
+	// Rejudge submissions in the background when problems change
+	rejudges := rejudge.New(db.Database, runner, compileCache)
+	rejudges.Start(ctx)
+
 	// Create router with database connection
-	r := router.NewWithDB(db.Database)
+	r := router.NewWithDB(db.Database, router.Services{
+		Runner:           runner,
+		Submissions:      submissions,
+		CompileCache:     compileCache,
+		DiagnosticsCache: diagnosticsCache,
+		Rejudges:         rejudges,
+	})
 
 	// Start server with TLS config that accepts self-signed certificates
 	srv := &http.Server{
@@ -80,6 +143,11 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop the submission workers; unfinished submissions resume on restart
+	cancel()
+	submissions.Wait()
+	rejudges.Wait()
+
 	log.Println("Server exited")
 }
 
@@ -1,9 +1,19 @@
 package cache
 
 import (
+	"container/list"
+	"context"
 	model "learning_go/internal/models"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/sync/singleflight"
 )
 
 // CompileCacheEntry represents a cached compile request and its response
@@ -13,69 +23,226 @@ type CompileCacheEntry struct {
 	CreatedAt    time.Time
 }
 
-// CompileCache is a thread-safe cache for compile requests
+// Stats reports how the cache has been used since it was created
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	// SharedHits counts misses in memory that were served by the shared tier
+	SharedHits uint64 `json:"sharedHits"`
+	// Coalesced counts requests that waited for an identical in-flight request
+	Coalesced uint64 `json:"coalesced"`
+}
+
+type lruItem struct {
+	key   string
+	entry *CompileCacheEntry
+}
+
+// CompileCache is a thread-safe, size-bounded LRU cache for compile
+// requests. An optional shared tier lets several API replicas reuse each
+// other's results.
 type CompileCache struct {
-	mu     sync.RWMutex
-	items  map[string]*CompileCacheEntry
-	maxAge time.Duration
+	mu       sync.Mutex
+	items    map[string]*list.Element
+	order    *list.List
+	capacity int
+	maxAge   time.Duration
+	shared   *MongoStore
+	stats    Stats
+	inflight singleflight.Group
 }
 
-// NewCompileCache creates a new compile cache with the specified max age
-func NewCompileCache(maxAge time.Duration) *CompileCache {
-	cache := &CompileCache{
-		items:  make(map[string]*CompileCacheEntry),
-		maxAge: maxAge,
+// NewCompileCache creates a compile cache holding at most capacity entries
+// for at most maxAge each. shared may be nil.
+func NewCompileCache(capacity int, maxAge time.Duration, shared *MongoStore) *CompileCache {
+	if capacity <= 0 {
+		capacity = 1000
 	}
+	return &CompileCache{
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		capacity: capacity,
+		maxAge:   maxAge,
+		shared:   shared,
+	}
+}
 
-	// Start cleanup goroutine
-	go cache.cleanup()
-
-	return cache
+// Key builds the cache key for a compile request. The problem revision is
+// part of the key, so editing a problem invalidates its cached verdicts.
+func Key(problemID string, revision time.Time, requestHash string) string {
+	return problemID + ":" + revision.UTC().Format(time.RFC3339Nano) + ":" + requestHash
 }
 
-// Get retrieves a cached response for the given request hash
-func (c *CompileCache) Get(hash string) (*CompileCacheEntry, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// DiagnosticsKey builds the cache key of compile-only diagnostics, which
+// depend on the code alone and not on any problem
+func DiagnosticsKey(codeHash string) string {
+	return "diagnostics:" + codeHash
+}
 
-	entry, exists := c.items[hash]
-	if !exists {
-		return nil, false
+// Get retrieves a cached response for the given key
+func (c *CompileCache) Get(ctx context.Context, key string) (*CompileCacheEntry, bool) {
+	c.mu.Lock()
+	if elem, exists := c.items[key]; exists {
+		entry := elem.Value.(*lruItem).entry
+		if time.Since(entry.CreatedAt) <= c.maxAge {
+			c.order.MoveToFront(elem)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return entry, true
+		}
+		c.removeElement(elem)
 	}
+	c.mu.Unlock()
 
-	// Check if entry is expired
-	if time.Since(entry.CreatedAt) > c.maxAge {
-		return nil, false
+	if c.shared != nil {
+		entry, err := c.shared.Get(ctx, key)
+		if err != nil {
+			log.Printf("Shared compile cache lookup failed: %v", err)
+		} else if entry != nil && time.Since(entry.CreatedAt) <= c.maxAge {
+			c.mu.Lock()
+			c.stats.SharedHits++
+			c.stats.Hits++
+			c.add(key, entry)
+			c.mu.Unlock()
+			return entry, true
+		}
 	}
 
-	return entry, true
-}
-
-// Set stores a response in the cache
-func (c *CompileCache) Set(hash string, responseBody model.CompileResponse, statusCode int) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.stats.Misses++
+	c.mu.Unlock()
+	return nil, false
+}
 
-	c.items[hash] = &CompileCacheEntry{
+// Set stores a response in the cache and returns the new entry
+func (c *CompileCache) Set(ctx context.Context, key string, responseBody model.CompileResponse, statusCode int) *CompileCacheEntry {
+	entry := &CompileCacheEntry{
 		ResponseBody: responseBody,
 		StatusCode:   statusCode,
 		CreatedAt:    time.Now(),
 	}
+
+	c.mu.Lock()
+	c.add(key, entry)
+	c.mu.Unlock()
+
+	if c.shared != nil {
+		if err := c.shared.Set(ctx, key, entry); err != nil {
+			log.Printf("Shared compile cache write failed: %v", err)
+		}
+	}
+	return entry
 }
 
-// cleanup periodically removes expired entries
-func (c *CompileCache) cleanup() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
+// Do returns the cached response for key. On a miss, compute is called and
+// its response cached; concurrent callers with the same key wait for that
+// single call and share its result instead of calling compute themselves.
+func (c *CompileCache) Do(ctx context.Context, key string, compute func() (*model.CompileResponse, error)) (*CompileCacheEntry, error) {
+	if entry, exists := c.Get(ctx, key); exists {
+		return entry, nil
+	}
+
+	leader := false
+	value, err, _ := c.inflight.Do(key, func() (interface{}, error) {
+		leader = true
 
-	for range ticker.C {
+		// Another caller may have filled the cache while we were checking it
 		c.mu.Lock()
-		now := time.Now()
-		for hash, entry := range c.items {
-			if now.Sub(entry.CreatedAt) > c.maxAge {
-				delete(c.items, hash)
-			}
+		if elem, exists := c.items[key]; exists {
+			entry := elem.Value.(*lruItem).entry
+			c.mu.Unlock()
+			return entry, nil
 		}
 		c.mu.Unlock()
+
+		response, err := compute()
+		if err != nil {
+			return nil, err
+		}
+		return c.Set(ctx, key, *response, http.StatusOK), nil
+	})
+
+	if !leader {
+		c.mu.Lock()
+		c.stats.Coalesced++
+		c.mu.Unlock()
 	}
+	if err != nil {
+		return nil, err
+	}
+	return value.(*CompileCacheEntry), nil
+}
+
+// InvalidateProblem drops every cached response for a problem
+func (c *CompileCache) InvalidateProblem(ctx context.Context, problemID string) error {
+	prefix := problemID + ":"
+
+	c.mu.Lock()
+	for key, elem := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(elem)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.shared != nil {
+		return c.shared.DeletePrefix(ctx, prefix)
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the cache counters
+func (c *CompileCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
+
+// add inserts or refreshes an entry, evicting the least recently used
+// entries beyond capacity. Callers must hold c.mu.
+func (c *CompileCache) add(key string, entry *CompileCacheEntry) {
+	if elem, exists := c.items[key]; exists {
+		elem.Value.(*lruItem).entry = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem{key: key, entry: entry})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// removeElement deletes an entry. Callers must hold c.mu.
+func (c *CompileCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*lruItem).key)
+}
+
+// NewFromEnv creates the compile cache configured by COMPILE_CACHE_SIZE and
+// COMPILE_CACHE_SHARED. When COMPILE_CACHE_SHARED is "true", entries are also
+// stored in MongoDB so that every API replica can reuse them.
+func NewFromEnv(ctx context.Context, db *mongo.Database) (*CompileCache, error) {
+	const maxAge = 24 * time.Hour
+
+	capacity, _ := strconv.Atoi(os.Getenv("COMPILE_CACHE_SIZE"))
+
+	var shared *MongoStore
+	if os.Getenv("COMPILE_CACHE_SHARED") == "true" {
+		var err error
+		shared, err = NewMongoStore(ctx, db, maxAge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewCompileCache(capacity, maxAge, shared), nil
 }
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoEntry is the document stored for each cached compile response
+type mongoEntry struct {
+	Key   string            `bson:"_id"`
+	Entry CompileCacheEntry `bson:"entry"`
+	// ExpiresAt drives the TTL index that purges stale entries
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// MongoStore is the shared cache tier, backed by the compile_cache collection
+type MongoStore struct {
+	Collection *mongo.Collection
+	maxAge     time.Duration
+}
+
+// NewMongoStore creates the shared tier and its TTL index
+func NewMongoStore(ctx context.Context, db *mongo.Database, maxAge time.Duration) (*MongoStore, error) {
+	store := &MongoStore{
+		Collection: db.Collection("compile_cache"),
+		maxAge:     maxAge,
+	}
+
+	_, err := store.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Get returns the entry stored under key, or nil when there is none
+func (s *MongoStore) Get(ctx context.Context, key string) (*CompileCacheEntry, error) {
+	var doc mongoEntry
+	err := s.Collection.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &doc.Entry, nil
+}
+
+// Set stores entry under key, replacing any previous value
+func (s *MongoStore) Set(ctx context.Context, key string, entry *CompileCacheEntry) error {
+	doc := mongoEntry{
+		Key:       key,
+		Entry:     *entry,
+		ExpiresAt: entry.CreatedAt.Add(s.maxAge),
+	}
+	_, err := s.Collection.ReplaceOne(ctx, bson.M{"_id": key}, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeletePrefix removes every entry whose key starts with prefix
+func (s *MongoStore) DeletePrefix(ctx context.Context, prefix string) error {
+	filter := bson.M{"_id": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+	_, err := s.Collection.DeleteMany(ctx, filter)
+	return err
+}
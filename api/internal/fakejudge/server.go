@@ -0,0 +1,293 @@
+// Package fakejudge implements the /performTestCases contract of the judge
+// service without compiling anything, for offline development and tests.
+// Clients that accept judge.StreamContentType receive each test case's
+// result as soon as it is ready.
+//
+// Each request is answered according to a behavior. The default behavior is
+// set when creating the server, and programs can override it with directives
+// in comments, one per line:
+//
+//	// fakejudge: echo                     answer every test case with its expected output
+//	// fakejudge: compile-error 3:7 msg    fail to compile at line 3, column 7
+//	// fakejudge: runtime-error msg        crash on every test case
+//	// fakejudge: delay 2s                 wait before answering (combines with the others);
+//	                                       test cases report it as their running time and
+//	                                       exceed the request's time limit if it is shorter
+//	// fakejudge: case-delay 500ms         wait before each test case's result
+//	// fakejudge: status 500               reply with an HTTP error
+//	// fakejudge: malformed                reply with invalid JSON
+package fakejudge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Behaviors
+const (
+	Echo         = "echo"
+	CompileError = "compile-error"
+	RuntimeError = "runtime-error"
+	Status       = "status"
+	Malformed    = "malformed"
+	Delay        = "delay"
+	CaseDelay    = "case-delay"
+)
+
+// directivePrefix marks behavior directives in a program
+const directivePrefix = "fakejudge:"
+
+// Script is the behavior applied to one request
+type Script struct {
+	Behavior   string
+	Delay      time.Duration
+	CaseDelay  time.Duration
+	Line       int
+	Column     int
+	Message    string
+	StatusCode int
+}
+
+// ParseScript reads the directives of program, starting from defaults
+func ParseScript(program string, defaults Script) (Script, error) {
+	script := defaults
+	for _, line := range strings.Split(program, "\n") {
+		_, directive, found := strings.Cut(line, directivePrefix)
+		if !found {
+			continue
+		}
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+
+		behavior, args := fields[0], fields[1:]
+		message := strings.Join(args, " ")
+		switch behavior {
+		case Echo, Malformed:
+			script.Behavior = behavior
+		case Delay, CaseDelay:
+			if len(args) != 1 {
+				return script, fmt.Errorf("%s expects a duration", behavior)
+			}
+			delay, err := time.ParseDuration(args[0])
+			if err != nil {
+				return script, fmt.Errorf("invalid %s %q", behavior, args[0])
+			}
+			if behavior == Delay {
+				script.Delay = delay
+			} else {
+				script.CaseDelay = delay
+			}
+		case CompileError:
+			if len(args) == 0 {
+				return script, fmt.Errorf("compile-error expects LINE:COLUMN")
+			}
+			lineText, columnText, _ := strings.Cut(args[0], ":")
+			lineNumber, err := strconv.Atoi(lineText)
+			if err != nil {
+				return script, fmt.Errorf("invalid position %q", args[0])
+			}
+			column, _ := strconv.Atoi(columnText)
+			script.Behavior = behavior
+			script.Line = lineNumber
+			script.Column = column
+			script.Message = strings.Join(args[1:], " ")
+			if script.Message == "" {
+				script.Message = "syntax error"
+			}
+		case RuntimeError:
+			script.Behavior = behavior
+			script.Message = message
+			if script.Message == "" {
+				script.Message = "segmentation fault"
+			}
+		case Status:
+			code, err := strconv.Atoi(message)
+			if err != nil || code < 100 || code > 599 {
+				return script, fmt.Errorf("invalid status %q", message)
+			}
+			script.Behavior = behavior
+			script.StatusCode = code
+		default:
+			return script, fmt.Errorf("unknown behavior %q", behavior)
+		}
+	}
+	return script, nil
+}
+
+// Server answers judge requests according to their script
+type Server struct {
+	// Defaults is the script of programs without directives
+	Defaults Script
+
+	expected map[string]interface{}
+}
+
+// NewServer creates a fake judge answering with defaults. Echoed outputs are
+// looked up in problems by function name and arguments.
+func NewServer(defaults Script, problems []*model.Problem) *Server {
+	s := &Server{Defaults: defaults, expected: make(map[string]interface{})}
+	for _, problem := range problems {
+		for i := range problem.TestCases {
+			args, err := problem.TestCaseArgs(i)
+			if err != nil {
+				log.Printf("Skipping test case %d of %q: %v", i, problem.Title, err)
+				continue
+			}
+			expected, err := problem.ExpectedOutput(i)
+			if err != nil {
+				log.Printf("Skipping test case %d of %q: %v", i, problem.Title, err)
+				continue
+			}
+			s.expected[expectationKey(problem.FunctionName, args)] = expected
+		}
+	}
+	return s
+}
+
+// expectationKey identifies a call by function name and JSON-encoded arguments
+func expectationKey(funName string, args []interface{}) string {
+	encoded, _ := json.Marshal(args)
+	return funName + string(encoded)
+}
+
+// ServeHTTP implements POST /performTestCases
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req judge.Request
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+		return
+	}
+
+	script, err := ParseScript(req.Program, s.Defaults)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if script.Delay > 0 {
+		select {
+		case <-time.After(script.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch script.Behavior {
+	case Status:
+		http.Error(w, http.StatusText(script.StatusCode), script.StatusCode)
+		return
+	case Malformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"results": [{"output": `))
+		return
+	}
+
+	if !req.CompileOnly && strings.Contains(r.Header.Get("Accept"), judge.StreamContentType) {
+		s.stream(w, r, &req, script)
+		return
+	}
+
+	response := s.respond(r.Context(), &req, script, func(judge.Result) {})
+	if r.Context().Err() != nil {
+		return
+	}
+
+	var body bytes.Buffer
+	json.NewEncoder(&body).Encode(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// stream writes one judge.StreamLine per test case as soon as it is ready
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, req *judge.Request, script Script) {
+	w.Header().Set("Content-Type", judge.StreamContentType)
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	controller := http.NewResponseController(w)
+	response := s.respond(r.Context(), req, script, func(result judge.Result) {
+		encoder.Encode(judge.StreamLine{Result: &result})
+		controller.Flush()
+	})
+	if len(response.Diagnostics) > 0 {
+		encoder.Encode(judge.StreamLine{Diagnostics: response.Diagnostics})
+	}
+}
+
+// respond builds the judge response for req under script, passing every
+// result to emit as soon as it is ready. It stops early when ctx is done.
+func (s *Server) respond(ctx context.Context, req *judge.Request, script Script, emit func(judge.Result)) judge.Response {
+	response := judge.Response{Results: []judge.Result{}}
+
+	if script.Behavior == CompileError {
+		response.Diagnostics = []model.Diagnostic{{
+			Severity: model.SeverityError,
+			Line:     script.Line,
+			Column:   script.Column,
+			Message:  script.Message,
+		}}
+	}
+	if req.CompileOnly {
+		if response.Diagnostics == nil {
+			response.Diagnostics = []model.Diagnostic{}
+		}
+		return response
+	}
+
+	for _, args := range req.TestCases {
+		if script.CaseDelay > 0 {
+			select {
+			case <-time.After(script.CaseDelay):
+			case <-ctx.Done():
+				return response
+			}
+		}
+
+		var result judge.Result
+		switch script.Behavior {
+		case CompileError:
+			result = judge.Result{
+				Error:     script.Message,
+				Line:      script.Line,
+				Column:    script.Column,
+				ErrorType: judge.ErrorCompile,
+			}
+		case RuntimeError:
+			result = judge.Result{Error: script.Message, ErrorType: judge.ErrorRuntime}
+		default:
+			result = judge.Result{Output: 0}
+			if expected, ok := s.expected[expectationKey(req.FunName, args)]; ok {
+				result.Output = expected
+			}
+		}
+		if script.Delay > 0 && script.Behavior != CompileError {
+			result.TimeMs = float64(script.Delay.Milliseconds())
+			if req.TimeLimitMs > 0 && result.TimeMs > float64(req.TimeLimitMs) {
+				result = judge.Result{Error: "time limit exceeded", ErrorType: judge.ErrorTimeout, TimeMs: result.TimeMs}
+			}
+		}
+		emit(result)
+		response.Results = append(response.Results, result)
+	}
+	return response
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	model "learning_go/internal/models"
+	"log"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// writeProblemError reports a failed problem write: validation errors are
+// the client's fault and are returned as is
+func writeProblemError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidProblem):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err == mongo.ErrNoDocuments:
+		http.Error(w, "Problem not found", http.StatusNotFound)
+	default:
+		log.Printf("Failed to save problem: %v", err)
+		http.Error(w, "Failed to save problem", http.StatusInternalServerError)
+	}
+}
+
+// CreateProblem validates and stores a new problem
+func CreateProblem(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var problem model.Problem
+		if err := json.NewDecoder(r.Body).Decode(&problem); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		problem.ID = primitive.NilObjectID
+
+		problemService := model.NewProblemService(db)
+		if err := problemService.CreateProblem(ctx, &problem); err != nil {
+			writeProblemError(w, err)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/problems/"+problem.ID.Hex())
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(problem)
+	}
+}
+
+// UpdateProblem replaces a problem. With PATCH, only the fields present in
+// the body are changed, each one replaced as a whole.
+func UpdateProblem(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		problemService := model.NewProblemService(db)
+		current, err := problemService.GetProblemByID(ctx, r.PathValue("id"))
+		if err != nil {
+			log.Printf("Problem not found: %v", err)
+			http.Error(w, "Problem not found", http.StatusNotFound)
+			return
+		}
+
+		// PATCH replaces the fields present in the body, PUT the whole problem
+		problem := &model.Problem{}
+		if r.Method == http.MethodPatch {
+			patch, err := io.ReadAll(r.Body)
+			if err == nil {
+				problem, err = current.Patch(patch)
+			}
+			if err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		} else if err := json.NewDecoder(r.Body).Decode(problem); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		problem.ID = current.ID
+
+		if err := problemService.UpdateProblem(ctx, problem); err != nil {
+			writeProblemError(w, err)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(problem)
+	}
+}
+
+// DeleteProblem removes a problem
+func DeleteProblem(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		problemService := model.NewProblemService(db)
+		if err := problemService.DeleteProblem(ctx, r.PathValue("id")); err != nil {
+			if err == mongo.ErrNoDocuments {
+				http.Error(w, "Problem not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("Failed to delete problem: %v", err)
+			http.Error(w, "Failed to delete problem", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
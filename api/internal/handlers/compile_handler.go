@@ -1,17 +1,21 @@
 package handler
 
 import (
-	"bytes"
+	"context"
 	"crypto/sha256"
-	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
-	"io"
+	"errors"
+	"fmt"
+	"learning_go/internal/cache"
+	"learning_go/internal/judge"
+	"learning_go/internal/middleware"
 	model "learning_go/internal/models"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -19,16 +23,111 @@ import (
 type compileBody struct {
 	ID   string `json:"problemId"`
 	Code string `json:"code"`
+	// Mode is "submit" (default) to judge every test case or "run" for samples only
+	Mode string `json:"mode,omitempty"`
+	// Language is the language Code is written in, model.DefaultLanguage when empty
+	Language string `json:"language,omitempty"`
 }
 
-type compileRequest struct {
-	Program   string          `json:"program"`
-	FunName   string          `json:"funName"`
-	TestCases [][]interface{} `json:"testCases"`
+// Compile modes
+const (
+	compileModeSubmit = "submit"
+	compileModeRun    = "run"
+)
+
+// validMode reports whether body.Mode is a known compile mode
+func (body compileBody) validMode() bool {
+	return body.Mode == "" || body.Mode == compileModeSubmit || body.Mode == compileModeRun
+}
+
+// selectTestCases narrows problem to the test cases judged in body's mode
+func (body compileBody) selectTestCases(problem *model.Problem) *model.Problem {
+	if body.Mode == compileModeRun {
+		return problem.Samples()
+	}
+	return problem
+}
+
+// cacheKey returns the compile cache key for body judged against problem
+func (body compileBody) cacheKey(problem *model.Problem) string {
+	body.Language = model.NormalizeLanguage(body.Language)
+	bodyBytes, _ := json.Marshal(body)
+	hash := sha256.Sum256(bodyBytes)
+	return cache.Key(problem.ID.Hex(), problem.UpdatedAt, hex.EncodeToString(hash[:]))
+}
+
+// languageError returns why code in language cannot be judged against
+// problem, or "" when it can. supported tells whether a judge runner is
+// registered for language.
+func languageError(supported bool, problem *model.Problem, language string) string {
+	if !supported {
+		return "Unsupported language"
+	}
+	if !problem.AllowsLanguage(language) {
+		return "Language not allowed for this problem"
+	}
+	return ""
+}
+
+// judgeErrorStatus maps a judge failure to the HTTP status and message
+// returned to the client, and how long the client should wait before retrying
+func judgeErrorStatus(err error) (int, string, time.Duration) {
+	var openErr *judge.CircuitOpenError
+	if errors.As(err, &openErr) {
+		return http.StatusServiceUnavailable, "Compile service is temporarily unavailable", openErr.RetryAfter
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, "Compile service timed out", 0
+	}
+	return http.StatusServiceUnavailable, "Compile service unavailable", 0
+}
+
+// writeJudgeError reports a failed judge call to the client
+func writeJudgeError(w http.ResponseWriter, err error) {
+	log.Printf("Judge request failed: %v", err)
+
+	status, message, retryAfter := judgeErrorStatus(err)
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
+
+// recordSubmission stores a judged compile request in the submissions
+// collection, which backs the user's solution history. Sample-only runs are
+// not submissions and are skipped.
+func recordSubmission(db *mongo.Database, r *http.Request, body compileBody, problem *model.Problem, result model.CompileResponse, startedAt time.Time) {
+	if body.Mode == compileModeRun {
+		return
+	}
+	username, ok := r.Context().Value(middleware.UsernameKey).(string)
+	if !ok {
+		return
+	}
+
+	submission := &model.Submission{
+		Username:  username,
+		ProblemID: problem.ID,
+		Code:      body.Code,
+		Language:  model.NormalizeLanguage(body.Language),
+		CreatedAt: startedAt,
+		StartedAt: &startedAt,
+	}
+	if user, err := model.NewUserService(db).GetUserByUsername(ctx, username); err == nil {
+		submission.UserID = user.ID
+	}
+
+	if err := model.NewSubmissionService(db).RecordSubmission(ctx, submission, &result); err != nil {
+		log.Printf("Failed to record submission: %v", err)
+	}
 }
 
 // GetFullCompile handles code compilation requests with caching
-func GetFullCompile(db *mongo.Database) http.HandlerFunc {
+func GetFullCompile(db *mongo.Database, runner judge.Runner, compileCache *cache.CompileCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Validate HTTP method
 		if r.Method != http.MethodPost {
@@ -45,23 +144,13 @@ func GetFullCompile(db *mongo.Database) http.HandlerFunc {
 		}
 		defer r.Body.Close()
 
-		log.Printf("Compile endpoint received: ProblemID=%s, Code=%s", body.ID, body.Code)
-
-		// Create hash of request body for caching
-		bodyBytes, _ := json.Marshal(body)
-		hash := sha256.Sum256(bodyBytes)
-		hashStr := hex.EncodeToString(hash[:])
-
-		// Check cache first
-		if cached, exists := compileCache.Get(hashStr); exists {
-			log.Printf("Cache hit for compile request: %s", hashStr)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(cached.StatusCode)
-			json.NewEncoder(w).Encode(cached.ResponseBody)
+		if !body.validMode() {
+			http.Error(w, "Invalid mode", http.StatusBadRequest)
 			return
 		}
 
-		log.Printf("Cache miss for compile request: %s", hashStr)
+		log.Printf("Compile endpoint received: ProblemID=%s, Code=%s", body.ID, body.Code)
+		startedAt := time.Now()
 
 		problemService := model.NewProblemService(db)
 		problem, err := problemService.GetProblemByID(ctx, body.ID)
@@ -72,170 +161,189 @@ func GetFullCompile(db *mongo.Database) http.HandlerFunc {
 			return
 		}
 
-		// Transform test cases to the expected format (inputs only)
-		var transformedTestCases [][]interface{}
-		for _, testCase := range problem.TestCases {
-			// Parse input string into individual parameters
-			inputs := strings.Fields(testCase.Input)
-			var inputParams []interface{}
-			for _, input := range inputs {
-				if val, err := strconv.Atoi(input); err == nil {
-					inputParams = append(inputParams, val)
-				} else {
-					inputParams = append(inputParams, input)
-				}
-			}
-
-			// Create test case with just inputs (no expected output)
-			transformedTestCases = append(transformedTestCases, inputParams)
-		}
-
-		// Create compile request body
-		compileReq := compileRequest{
-			Program:   body.Code,
-			FunName:   problem.FunctionName,
-			TestCases: transformedTestCases,
+		if message := languageError(judge.SupportsLanguage(runner, body.Language), problem, body.Language); message != "" {
+			http.Error(w, message, http.StatusBadRequest)
+			return
 		}
 
-		// Create HTTP client with TLS config to skip certificate verification
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		// Key the cache on the request and the problem revision
+		hashStr := body.cacheKey(problem)
+
+		problem = body.selectTestCases(problem)
+
+		// Serve from the cache, or run the code through the judge backend.
+		// Identical concurrent requests share a single judge call, which must
+		// not be cancelled when the client that started it goes away.
+		judgeCtx := context.WithoutCancel(r.Context())
+		cached, err := compileCache.Do(r.Context(), hashStr, func() (*model.CompileResponse, error) {
+			log.Printf("Cache miss for compile request: %s", hashStr)
+			return judge.Evaluate(judgeCtx, runner, problem, body.Code, body.Language)
+		})
+		if errors.Is(err, judge.ErrInvalidTestCases) {
+			log.Printf("Problem %s has invalid test cases: %v", body.ID, err)
+			http.Error(w, "Problem has invalid test cases", http.StatusInternalServerError)
+			return
 		}
-		client := &http.Client{Transport: tr}
-
-		// Create request to compile service with the body
-		compileReqBytes, err := json.Marshal(compileReq)
 		if err != nil {
-			http.Error(w, "Failed to marshal compile request", http.StatusInternalServerError)
+			writeJudgeError(w, err)
 			return
 		}
 
-		req, err := http.NewRequest("POST", "https://172.16.30.3:3001/performTestCases", bytes.NewBuffer(compileReqBytes))
-		req.Header.Set("Content-Type", "application/json")
-		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]string{
-				"error": "Failed to create request",
-			})
+		recordSubmission(db, r, body, problem, cached.ResponseBody, startedAt)
+
+		// Set response headers
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(cached.StatusCode)
+
+		// Write response back to client
+		json.NewEncoder(w).Encode(cached.ResponseBody)
+	}
+}
+
+// writeEvent sends a single Server-Sent Event and flushes it to the client
+func writeEvent(w http.ResponseWriter, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return http.NewResponseController(w).Flush()
+}
+
+// StreamCompile judges code like GetFullCompile but streams the outcome as
+// Server-Sent Events: one "result" event per test case as soon as the judge
+// reports it (see judge.Stream), then a "summary" event with the overall
+// status and first error. Responses are cached and submissions recorded like
+// GetFullCompile's; cached responses and requests that joined an identical
+// in-flight one replay their results when the judge has finished.
+func StreamCompile(db *mongo.Database, runner judge.Runner, compileCache *cache.CompileCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		// Print request details for debugging
-		log.Printf("Sending compile request to service: %s", req.URL.String())
-		log.Printf("Compile request body: %s", compileReqBytes)
+		var body compileBody
 
-		// Send request
-		resp, err := client.Do(req)
-		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]string{
-				"error": "Compile service unavailable",
-			})
+		// Read and parse request body
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
 			return
 		}
+		defer r.Body.Close()
 
-		defer resp.Body.Close()
-
-		// Read response body
-		respBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]string{
-				"error": "Failed to read response",
-			})
+		if !body.validMode() {
+			http.Error(w, "Invalid mode", http.StatusBadRequest)
 			return
 		}
 
-		var response struct {
-			Results []struct {
-				Output int    `json:"output"`
-				Error  string `json:"error"`
-				Line   int    `json:"line"`
-				Column int    `json:"column"`
-			} `json:"results"`
+		startedAt := time.Now()
+		problemService := model.NewProblemService(db)
+		problem, err := problemService.GetProblemByID(ctx, body.ID)
+		if err != nil {
+			log.Printf("Problem not found: %v", err)
+			http.Error(w, "Problem not found", http.StatusNotFound)
+			return
 		}
 
-		if err := json.Unmarshal(respBody, &response); err != nil {
-			http.Error(w, "Failed to parse response", http.StatusInternalServerError)
+		if message := languageError(judge.SupportsLanguage(runner, body.Language), problem, body.Language); message != "" {
+			http.Error(w, message, http.StatusBadRequest)
 			return
 		}
 
-		var structuredResponse model.CompileResponse
-		// Actual response
-		log.Printf("Compile service response: %+v", response)
-
-		// Process all test results
-		var results []model.CompileResults
-		var firstError string
-		var firstErrorLine, firstErrorColumn int
-		hasError := false
-
-		for i, result := range response.Results {
-			// Check for compilation error
-			if result.Error != "" {
-				if !hasError {
-					hasError = true
-					firstError = result.Error
-					firstErrorLine = result.Line
-					firstErrorColumn = result.Column
-				}
-				results = append(results, model.CompileResults{
-					Status:         "Failed",
-					Output:         []int{result.Output},
-					ExpectedOutput: []int{},
-				})
-				continue
+		hashStr := body.cacheKey(problem)
+		problem = body.selectTestCases(problem)
+
+		// Set SSE headers
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		// Like GetFullCompile, identical concurrent requests share a single
+		// judge call that outlives the client that started it. Only that
+		// client sees the results as they are judged.
+		judgeCtx := context.WithoutCancel(r.Context())
+		streamed := false
+		cached, err := compileCache.Do(r.Context(), hashStr, func() (*model.CompileResponse, error) {
+			log.Printf("Cache miss for compile stream: %s", hashStr)
+			streamed = true
+			return judge.EvaluateStream(judgeCtx, runner, problem, body.Code, body.Language, func(result model.CompileResults) {
+				// A client that went away must not stop the shared call
+				writeEvent(w, "result", result)
+			})
+		})
+		if err != nil {
+			log.Printf("Compile stream failed: %v", err)
+			_, message, retryAfter := judgeErrorStatus(err)
+			if errors.Is(err, judge.ErrInvalidTestCases) {
+				message = "Problem has invalid test cases"
 			}
+			writeEvent(w, "error", map[string]interface{}{
+				"error":      message,
+				"retryAfter": int(math.Ceil(retryAfter.Seconds())),
+			})
+			return
+		}
 
-			// Compare with expected output
-			expectedOutput := 0
-			if i < len(problem.TestCases) {
-				if val, err := strconv.Atoi(problem.TestCases[i].Output); err == nil {
-					expectedOutput = val
+		if !streamed {
+			for _, result := range cached.ResponseBody.Result {
+				if err := writeEvent(w, "result", result); err != nil {
+					return
 				}
 			}
+		}
+		recordSubmission(db, r, body, problem, cached.ResponseBody, startedAt)
 
-			status := "Failed"
-			if result.Output == expectedOutput {
-				status = "Success"
-			} else {
-				hasError = true
-			}
+		summary := cached.ResponseBody
+		summary.Result = nil
+		writeEvent(w, "summary", summary)
+	}
+}
 
-			results = append(results, model.CompileResults{
-				Status:         status,
-				Output:         []int{result.Output},
-				ExpectedOutput: []int{expectedOutput},
-			})
+// GetCompileCacheStats reports the compile cache counters
+func GetCompileCacheStats(compileCache *cache.CompileCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
 		}
 
-		// Build response
-		structuredResponse = model.CompileResponse{
-			Result: results,
-			Error:  firstError,
-			Status: "Success",
-			Line:   firstErrorLine,
-			Column: firstErrorColumn,
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(compileCache.Stats())
+	}
+}
+
+// GetHealth reports whether the API is up and the state of the judge backend
+func GetHealth(runner judge.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
 		}
 
-		if hasError {
-			structuredResponse.Status = "Error"
+		response := map[string]interface{}{
+			"status": "ok",
 		}
 
-		// Cache successful responses
-		if resp.StatusCode == http.StatusOK {
-			compileCache.Set(hashStr, structuredResponse, resp.StatusCode)
-			log.Printf("Cached compile response for request: %s", hashStr)
+		if reporter, ok := runner.(judge.HealthReporter); ok {
+			health := reporter.Health()
+			response["judge"] = health
+			if !health.Healthy {
+				response["status"] = "degraded"
+			}
 		}
 
-		// Set response headers
+		// Set content type and send response
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(resp.StatusCode)
-
-		// Write response back to client
-		json.NewEncoder(w).Encode(structuredResponse)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
 	}
 }
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"learning_go/internal/cache"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type diagnosticsBody struct {
+	Code string `json:"code"`
+	// Language is the language Code is written in, model.DefaultLanguage when empty
+	Language string `json:"language,omitempty"`
+}
+
+// GetDiagnostics compiles code without running it and returns every
+// diagnostic, so the editor can underline errors as the student types.
+// Results are cached by language and code hash, in a cache of their own so
+// they do not evict compile verdicts.
+func GetDiagnostics(runner judge.Runner, diagnosticsCache *cache.CompileCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body diagnosticsBody
+
+		// Read and parse request body
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if strings.TrimSpace(body.Code) == "" {
+			http.Error(w, "Code is required", http.StatusBadRequest)
+			return
+		}
+
+		language := model.NormalizeLanguage(body.Language)
+		if !judge.SupportsLanguage(runner, language) {
+			http.Error(w, "Unsupported language", http.StatusBadRequest)
+			return
+		}
+
+		hash := sha256.Sum256([]byte(language + "\x00" + body.Code))
+		key := cache.DiagnosticsKey(hex.EncodeToString(hash[:]))
+
+		// Identical concurrent requests share a single judge call
+		judgeCtx := context.WithoutCancel(r.Context())
+		cached, err := diagnosticsCache.Do(r.Context(), key, func() (*model.CompileResponse, error) {
+			log.Printf("Cache miss for diagnostics request: %s", key)
+			diagnostics, err := judge.Diagnose(judgeCtx, runner, body.Code, language)
+			if err != nil {
+				return nil, err
+			}
+
+			status := "Success"
+			if judge.HasErrors(diagnostics) {
+				status = "Error"
+			}
+			return &model.CompileResponse{Status: status, Diagnostics: diagnostics}, nil
+		})
+		if errors.Is(err, judge.ErrNoDiagnostics) {
+			// Not cached: a judge that compiles the code may answer next time
+			cached = &cache.CompileCacheEntry{ResponseBody: model.CompileResponse{Status: "Unknown"}}
+		} else if err != nil {
+			writeJudgeError(w, err)
+			return
+		}
+
+		diagnostics := cached.ResponseBody.Diagnostics
+		if diagnostics == nil {
+			diagnostics = []model.Diagnostic{}
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(model.DiagnosticsResponse{
+			Status:      cached.ResponseBody.Status,
+			Diagnostics: diagnostics,
+		})
+	}
+}
@@ -63,9 +63,9 @@ func GetUserSolutions(db *mongo.Database) http.HandlerFunc {
 			return
 		}
 
-		// Query user solutions from logs
-		logsService := model.NewLogsService(db)
-		solutions, err := logsService.GetUserSolutionsByProblem(ctx, username, problemID)
+		// Query user solutions from submissions
+		submissionService := model.NewSubmissionService(db)
+		solutions, err := submissionService.GetUserSolutionsByProblem(ctx, username, problemID)
 		if err != nil {
 			http.Error(w, "Failed to retrieve solutions", http.StatusInternalServerError)
 			return
@@ -76,6 +76,7 @@ func GetUserSolutions(db *mongo.Database) http.HandlerFunc {
 			"success":        true,
 			"solutions":      solutions,
 			"totalSolutions": len(solutions),
+			"bestScore":      model.BestScores(solutions)[problemID],
 		}
 
 		// Set content type and send response
@@ -101,9 +102,9 @@ func GetAllUserSolutions(db *mongo.Database) http.HandlerFunc {
 			return
 		}
 
-		// Query user solutions from logs
-		logsService := model.NewLogsService(db)
-		solutions, err := logsService.GetAllUserSolutions(ctx, username)
+		// Query user solutions from submissions
+		submissionService := model.NewSubmissionService(db)
+		solutions, err := submissionService.GetAllUserSolutions(ctx, username)
 		if err != nil {
 			http.Error(w, "Failed to retrieve solutions", http.StatusInternalServerError)
 			return
@@ -114,6 +115,7 @@ func GetAllUserSolutions(db *mongo.Database) http.HandlerFunc {
 			"success":        true,
 			"solutions":      solutions,
 			"totalSolutions": len(solutions),
+			"bestScores":     model.BestScores(solutions),
 		}
 
 		// Set content type and send response
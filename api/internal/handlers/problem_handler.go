@@ -3,8 +3,13 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"learning_go/internal/middleware"
 	model "learning_go/internal/models"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -60,7 +65,7 @@ func GetProblemByID(db *mongo.Database) http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 
 		// Encode and send response
-		if err := json.NewEncoder(w).Encode(problem); err != nil {
+		if err := json.NewEncoder(w).Encode(problem.Public()); err != nil {
 			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 			return
 		}
@@ -75,12 +80,24 @@ func GetAllProblems(db *mongo.Database) http.HandlerFunc {
 			return
 		}
 
+		query, err := parseProblemQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		// Initialize problem service
 		problemService := model.NewProblemService(db)
-		
-		// Get all problems from database
-		problems, err := problemService.GetAllProblems(context.Background())
+
+		// Get the requested page of problem summaries from database, with
+		// the user's status on each one
+		username, _ := r.Context().Value(middleware.UsernameKey).(string)
+		page, err := problemService.ListProblems(context.Background(), query, username)
 		if err != nil {
+			if errors.Is(err, model.ErrInvalidQuery) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -90,9 +107,51 @@ func GetAllProblems(db *mongo.Database) http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 
 		// Encode and send response
-		if err := json.NewEncoder(w).Encode(problems); err != nil {
+		if err := json.NewEncoder(w).Encode(page); err != nil {
 			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 			return
 		}
 	}
-}
\ No newline at end of file
+}
+
+// parseProblemQuery reads the problem list parameters, e.g.
+// ?difficulty=easy,medium&tags=loops&q=sum&sort=-acceptance&limit=20&cursor=...
+// A leading "-" on sort reverses the order.
+func parseProblemQuery(r *http.Request) (model.ProblemQuery, error) {
+	params := r.URL.Query()
+	query := model.ProblemQuery{
+		Difficulties: splitParam(params.Get("difficulty")),
+		Tags:         splitParam(params.Get("tags")),
+		Search:       params.Get("q"),
+	}
+
+	query.Sort, query.Descending = strings.CutPrefix(params.Get("sort"), "-")
+
+	if limit := params.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit %q", limit)
+		}
+		query.Limit = value
+	}
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		offset, err := model.DecodeCursor(cursor)
+		if err != nil {
+			return query, err
+		}
+		query.Offset = offset
+	}
+	return query, nil
+}
+
+// splitParam splits a comma-separated query parameter, dropping empty entries
+func splitParam(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
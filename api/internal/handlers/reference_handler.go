@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type referenceBody struct {
+	Solution string `json:"solution"`
+	Language string `json:"language"`
+}
+
+// SetReferenceSolution stores the hidden reference solution of a problem
+func SetReferenceSolution(db *mongo.Database, runner judge.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPut {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body referenceBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(body.Solution) == "" {
+			http.Error(w, "Reference solution is required", http.StatusBadRequest)
+			return
+		}
+
+		problemService := model.NewProblemService(db)
+		problem, err := problemService.GetProblemByID(ctx, r.PathValue("id"))
+		if err != nil {
+			log.Printf("Problem not found: %v", err)
+			http.Error(w, "Problem not found", http.StatusNotFound)
+			return
+		}
+
+		if message := languageError(judge.SupportsLanguage(runner, body.Language), problem, body.Language); message != "" {
+			http.Error(w, message, http.StatusBadRequest)
+			return
+		}
+
+		if err := problemService.SetReferenceSolution(ctx, problem.ID, body.Solution, body.Language); err != nil {
+			log.Printf("Failed to store reference solution: %v", err)
+			http.Error(w, "Failed to store reference solution", http.StatusInternalServerError)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"language": model.NormalizeLanguage(body.Language),
+		})
+	}
+}
+
+// CheckReferenceSolution runs a problem's reference solution on every test
+// case and reports where it disagrees with the stored expected outputs.
+// With ?fill=true missing expected outputs are generated, and with
+// ?overwrite=true mismatched ones are replaced as well.
+func CheckReferenceSolution(db *mongo.Database, runner judge.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		fill := r.URL.Query().Get("fill") == "true"
+		overwrite := r.URL.Query().Get("overwrite") == "true"
+
+		problemService := model.NewProblemService(db)
+		problem, err := problemService.GetProblemByID(ctx, r.PathValue("id"))
+		if err != nil {
+			log.Printf("Problem not found: %v", err)
+			http.Error(w, "Problem not found", http.StatusNotFound)
+			return
+		}
+
+		report, err := judge.CheckReference(r.Context(), runner, problem)
+		if err == judge.ErrNoReferenceSolution {
+			http.Error(w, "Problem has no reference solution", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, judge.ErrInvalidTestCases) {
+			log.Printf("Problem %s has invalid test cases: %v", problem.ID.Hex(), err)
+			http.Error(w, "Problem has invalid test cases", http.StatusInternalServerError)
+			return
+		}
+		if err != nil {
+			writeJudgeError(w, err)
+			return
+		}
+
+		updated := 0
+		if fill || overwrite {
+			updated = report.Apply(problem, overwrite)
+		}
+		if updated > 0 {
+			if err := problemService.UpdateTestCases(ctx, problem); err != nil {
+				log.Printf("Failed to update test cases: %v", err)
+				http.Error(w, "Failed to update test cases", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"report":  report,
+			"updated": updated,
+		})
+	}
+}
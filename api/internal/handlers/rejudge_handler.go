@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"learning_go/internal/middleware"
+	model "learning_go/internal/models"
+	"learning_go/internal/rejudge"
+	"log"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StartRejudge rejudges every stored submission of a problem in the background
+func StartRejudge(db *mongo.Database, rejudges *rejudge.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Get username from context (set by auth middleware)
+		username, ok := r.Context().Value(middleware.UsernameKey).(string)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		problemService := model.NewProblemService(db)
+		problem, err := problemService.GetProblemByID(ctx, r.PathValue("id"))
+		if err != nil {
+			log.Printf("Problem not found: %v", err)
+			http.Error(w, "Problem not found", http.StatusNotFound)
+			return
+		}
+
+		job, err := rejudges.Rejudge(ctx, problem, username)
+		if err == rejudge.ErrAlreadyRunning {
+			http.Error(w, "A rejudge is already running for this problem", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to start rejudge: %v", err)
+			http.Error(w, "Failed to start rejudge", http.StatusInternalServerError)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/rejudges/"+job.ID.Hex())
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+// GetRejudge returns the progress and summary of a rejudge job
+func GetRejudge(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		rejudgeService := model.NewRejudgeService(db)
+		job, err := rejudgeService.GetJobByID(ctx, r.PathValue("id"))
+		if err != nil {
+			if err.Error() == "invalid rejudge ID" || err.Error() == "rejudge not found" {
+				http.Error(w, "Rejudge not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to retrieve rejudge", http.StatusInternalServerError)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(job)
+	}
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxRunInputs limits how many input tuples a single playground run may carry
+const maxRunInputs = 20
+
+type runBody struct {
+	ID     string          `json:"problemId"`
+	Code   string          `json:"code"`
+	Inputs [][]interface{} `json:"inputs"`
+	// Language is the language Code is written in, model.DefaultLanguage when empty
+	Language string `json:"language,omitempty"`
+}
+
+// RunCode runs code on user-supplied inputs and returns the raw outputs,
+// without comparing them to the problem's expected outputs
+func RunCode(db *mongo.Database, runner judge.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body runBody
+
+		// Read and parse request body, keeping numbers exact for type checks
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil {
+			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if strings.TrimSpace(body.Code) == "" {
+			http.Error(w, "Code is required", http.StatusBadRequest)
+			return
+		}
+		if len(body.Inputs) == 0 {
+			http.Error(w, "At least one input is required", http.StatusBadRequest)
+			return
+		}
+		if len(body.Inputs) > maxRunInputs {
+			http.Error(w, fmt.Sprintf("At most %d inputs are allowed", maxRunInputs), http.StatusBadRequest)
+			return
+		}
+
+		problemService := model.NewProblemService(db)
+		problem, err := problemService.GetProblemByID(ctx, body.ID)
+		if err != nil {
+			log.Printf("Problem not found: %v", err)
+			http.Error(w, "Problem not found", http.StatusNotFound)
+			return
+		}
+
+		if message := languageError(judge.SupportsLanguage(runner, body.Language), problem, body.Language); message != "" {
+			http.Error(w, message, http.StatusBadRequest)
+			return
+		}
+
+		// Validate every input tuple against the function's argument types
+		inputs := make([][]interface{}, 0, len(body.Inputs))
+		for i, input := range body.Inputs {
+			args, err := problem.ConvertArgs(input)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid input %d: %v", i, err), http.StatusBadRequest)
+				return
+			}
+			inputs = append(inputs, args)
+		}
+
+		problem = judge.LimitsOf(runner).Apply(problem)
+		response, err := judge.RunWithDeadline(r.Context(), runner, &judge.Request{
+			Program:       body.Code,
+			FunName:       problem.FunctionName,
+			TestCases:     inputs,
+			Language:      model.NormalizeLanguage(body.Language),
+			TimeLimitMs:   problem.TimeLimit(),
+			MemoryLimitKB: problem.MemoryLimit(),
+		})
+		if err != nil {
+			writeJudgeError(w, err)
+			return
+		}
+
+		// Every input must be answered, in order, or outputs would be
+		// reported against the wrong inputs
+		if len(response.Results) != len(inputs) {
+			log.Printf("Judge returned %d results for %d inputs", len(response.Results), len(inputs))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Compile service returned an incomplete response",
+			})
+			return
+		}
+
+		runResponse := model.RunResponse{Results: []model.RunResult{}}
+		for i, result := range response.Results {
+			output := result.Output
+			if converted, err := model.ConvertValue(problem.OutputType(), result.Output); err == nil {
+				output = converted
+			}
+			runResponse.Results = append(runResponse.Results, model.RunResult{
+				Input:  inputs[i],
+				Output: output,
+				Error:  result.Error,
+				Line:   result.Line,
+				Column: result.Column,
+			})
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(runResponse)
+	}
+}
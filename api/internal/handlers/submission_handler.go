@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"learning_go/internal/middleware"
+	model "learning_go/internal/models"
+	"learning_go/internal/queue"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SubmissionResponse is returned when a submission is accepted
+type SubmissionResponse struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
+
+// CreateSubmission validates a compile body and queues it for judging
+func CreateSubmission(db *mongo.Database, q *queue.Queue) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Get username from context (set by auth middleware)
+		username, ok := r.Context().Value(middleware.UsernameKey).(string)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		var body compileBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if strings.TrimSpace(body.Code) == "" {
+			http.Error(w, "Code is required", http.StatusBadRequest)
+			return
+		}
+
+		problemService := model.NewProblemService(db)
+		problem, err := problemService.GetProblemByID(ctx, body.ID)
+		if err != nil {
+			log.Printf("Problem not found: %v", err)
+			http.Error(w, "Problem not found", http.StatusNotFound)
+			return
+		}
+
+		if message := languageError(q.SupportsLanguage(body.Language), problem, body.Language); message != "" {
+			http.Error(w, message, http.StatusBadRequest)
+			return
+		}
+
+		submission := &model.Submission{
+			Username:  username,
+			ProblemID: problem.ID,
+			Code:      body.Code,
+			Language:  model.NormalizeLanguage(body.Language),
+		}
+
+		if user, err := model.NewUserService(db).GetUserByUsername(ctx, username); err == nil {
+			submission.UserID = user.ID
+		}
+
+		if err := q.Enqueue(ctx, submission); err != nil {
+			if err == queue.ErrQueueFull {
+				w.Header().Set("Retry-After", "5")
+				http.Error(w, "Submission queue is full", http.StatusServiceUnavailable)
+				return
+			}
+			log.Printf("Failed to queue submission: %v", err)
+			http.Error(w, "Failed to queue submission", http.StatusInternalServerError)
+			return
+		}
+
+		response := SubmissionResponse{
+			ID:    submission.ID.Hex(),
+			State: submission.State,
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/submissions/"+response.ID)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// GetSubmission returns the state of one of the user's submissions
+func GetSubmission(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.PathValue("id")
+		if id == "" {
+			http.Error(w, "Submission ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Get username from context (set by auth middleware)
+		username, ok := r.Context().Value(middleware.UsernameKey).(string)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		submissionService := model.NewSubmissionService(db)
+		submission, err := submissionService.GetSubmissionByID(ctx, id)
+		if err != nil {
+			if err.Error() == "invalid submission ID" {
+				http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+				return
+			}
+			if err.Error() == "submission not found" {
+				http.Error(w, "Submission not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("Failed to retrieve submission: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// Users can only see their own submissions
+		if submission.Username != username {
+			http.Error(w, "Submission not found", http.StatusNotFound)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(submission)
+	}
+}
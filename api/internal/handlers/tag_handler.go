@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	model "learning_go/internal/models"
+	"log"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetTags returns every tag with the number of problems assigned to it
+func GetTags(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		tagService := model.NewTagService(db)
+		tags, err := tagService.GetTagCounts(ctx)
+		if err != nil {
+			log.Printf("Failed to retrieve tags: %v", err)
+			http.Error(w, "Failed to retrieve tags", http.StatusInternalServerError)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(tags)
+	}
+}
+
+// CreateTag adds a tag problems can be assigned to
+func CreateTag(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var tag model.Tag
+		if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		tagService := model.NewTagService(db)
+		if err := tagService.CreateTag(ctx, &tag); err != nil {
+			if errors.Is(err, model.ErrInvalidTag) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err.Error() == "tag already exists" {
+				http.Error(w, "Tag already exists", http.StatusConflict)
+				return
+			}
+			log.Printf("Failed to create tag: %v", err)
+			http.Error(w, "Failed to create tag", http.StatusInternalServerError)
+			return
+		}
+
+		// Set content type and send response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(tag)
+	}
+}
+
+// DeleteTag removes a tag and unassigns it from every problem
+func DeleteTag(db *mongo.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Validate HTTP method
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		tagService := model.NewTagService(db)
+		if err := tagService.DeleteTag(ctx, r.PathValue("name")); err != nil {
+			if err == mongo.ErrNoDocuments {
+				http.Error(w, "Tag not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("Failed to delete tag: %v", err)
+			http.Error(w, "Failed to delete tag", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -4,11 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"learning_go/internal/auth"
-	"learning_go/internal/cache"
 	model "learning_go/internal/models"
 	"log"
 	"net/http"
-	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
@@ -22,8 +20,6 @@ type UserResponse struct {
 
 var ctx = context.Background()
 
-var compileCache = cache.NewCompileCache(24 * time.Hour)
-
 func SignUp(db *mongo.Database) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Validate HTTP method
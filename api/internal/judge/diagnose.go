@@ -0,0 +1,58 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	model "learning_go/internal/models"
+)
+
+// ErrNoDiagnostics is returned by Diagnose when the judge answers with
+// neither diagnostics nor results, as judges that ignore compileOnly do.
+// Whether the program compiles is then unknown.
+var ErrNoDiagnostics = errors.New("judge reported no diagnostics")
+
+// Diagnose asks the judge to compile program, written in language, without running it and returns
+// every diagnostic. Judges that only report the first error through a result
+// have it converted into a single diagnostic.
+func Diagnose(ctx context.Context, runner Runner, program, language string) ([]model.Diagnostic, error) {
+	response, err := runner.Run(ctx, &Request{
+		Program:     program,
+		TestCases:   [][]interface{}{},
+		Language:    model.NormalizeLanguage(language),
+		CompileOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Diagnostics) > 0 {
+		return response.Diagnostics, nil
+	}
+	if response.Diagnostics == nil && len(response.Results) == 0 {
+		return nil, ErrNoDiagnostics
+	}
+
+	diagnostics := []model.Diagnostic{}
+	for _, result := range response.Results {
+		if result.Error != "" {
+			diagnostics = append(diagnostics, model.Diagnostic{
+				Severity: model.SeverityError,
+				Line:     result.Line,
+				Column:   result.Column,
+				Message:  result.Error,
+			})
+			break
+		}
+	}
+	return diagnostics, nil
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diagnostics []model.Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == model.SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	model "learning_go/internal/models"
+	"log"
+	"time"
+)
+
+// ErrInvalidTestCases is returned when a problem's stored test cases do not
+// match its declared argument types
+var ErrInvalidTestCases = errors.New("invalid test cases")
+
+// BuildRequest converts a problem's test cases into a judge request for
+// code written in language, typing every argument according to Problem.Arguments
+func BuildRequest(problem *model.Problem, code, language string) (*Request, error) {
+	// Transform test cases to the expected format (inputs only)
+	testCases := make([][]interface{}, 0, len(problem.TestCases))
+	for i := range problem.TestCases {
+		args, err := problem.TestCaseArgs(i)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTestCases, err)
+		}
+		testCases = append(testCases, args)
+	}
+
+	return &Request{
+		Program:   code,
+		FunName:   problem.FunctionName,
+		TestCases: testCases,
+		Language:  model.NormalizeLanguage(language),
+		// Limits are enforced by the judge and bounded by our own deadline
+		TimeLimitMs:   problem.TimeLimit(),
+		MemoryLimitKB: problem.MemoryLimit(),
+	}, nil
+}
+
+// Grader compares judge results with a problem's expected outputs, one test
+// case at a time, and accumulates the response returned to the client
+type Grader struct {
+	problem  *model.Problem
+	response model.CompileResponse
+	hasError bool
+	verdict  model.Verdict
+	passed   []bool
+}
+
+// NewGrader creates a grader for problem
+func NewGrader(problem *model.Problem) *Grader {
+	return &Grader{problem: problem, passed: make([]bool, len(problem.TestCases))}
+}
+
+// Add grades the result of test case i and records it
+func (g *Grader) Add(i int, result Result) model.CompileResults {
+	result = g.enforceLimits(result)
+
+	// Check for compilation or runtime error
+	if result.Error != "" {
+		if !g.hasError {
+			g.hasError = true
+			g.response.Error = result.Error
+			g.response.Line = result.Line
+			g.response.Column = result.Column
+		}
+		graded := model.CompileResults{
+			Status:         "Failed",
+			Verdict:        result.Verdict(),
+			TimeMs:         result.TimeMs,
+			MemoryKB:       result.MemoryKB,
+			Output:         []interface{}{result.Output},
+			ExpectedOutput: []interface{}{},
+		}
+		return g.record(i, graded)
+	}
+
+	// Compare with expected output, both typed by the problem's return type,
+	// using the problem's comparator
+	verdict := model.VerdictWrongAnswer
+	output := result.Output
+	expectedOutput, err := g.problem.ExpectedOutput(i)
+	if err != nil {
+		log.Printf("Invalid expected output: %v", err)
+		verdict = model.VerdictInternalError
+	} else if converted, err := model.ConvertValue(g.problem.OutputType(), result.Output); err == nil {
+		output = converted
+		if g.problem.CompareOutput(expectedOutput, output) {
+			verdict = model.VerdictAccepted
+		}
+	}
+
+	status := "Success"
+	if verdict != model.VerdictAccepted {
+		status = "Failed"
+		g.hasError = true
+	}
+
+	graded := model.CompileResults{
+		Status:         status,
+		Verdict:        verdict,
+		TimeMs:         result.TimeMs,
+		MemoryKB:       result.MemoryKB,
+		Output:         []interface{}{output},
+		ExpectedOutput: []interface{}{expectedOutput},
+	}
+	return g.record(i, graded)
+}
+
+// enforceLimits turns a result that ran over the problem's limits into a
+// limit error, for judges that measure usage but do not enforce the limits
+func (g *Grader) enforceLimits(result Result) Result {
+	if result.Error != "" {
+		return result
+	}
+	if result.TimeMs > float64(g.problem.TimeLimit()) {
+		result.Error = timeLimitMessage
+		result.ErrorType = ErrorTimeout
+	} else if result.MemoryKB > g.problem.MemoryLimit() {
+		result.Error = "memory limit exceeded"
+		result.ErrorType = ErrorMemory
+	}
+	return result
+}
+
+// Missing records an internal error for test case i, which the judge did not answer
+func (g *Grader) Missing(i int) model.CompileResults {
+	g.hasError = true
+	return g.record(i, model.CompileResults{
+		Status:  "Failed",
+		Verdict: model.VerdictInternalError,
+	})
+}
+
+// record counts a graded test case and appends it to the response. Sample
+// cases show their input; hidden cases only report pass or fail.
+func (g *Grader) record(i int, graded model.CompileResults) model.CompileResults {
+	if i < len(g.problem.TestCases) && g.problem.TestCases[i].Sample {
+		if args, err := g.problem.TestCaseArgs(i); err == nil {
+			graded.Input = args
+		}
+	} else {
+		graded.Hidden = true
+		graded.Output = nil
+		graded.ExpectedOutput = nil
+	}
+
+	g.response.Total++
+	if graded.Status == "Success" {
+		g.response.Passed++
+		if i < len(g.passed) {
+			g.passed[i] = true
+		}
+	}
+	g.verdict = g.verdict.Worst(graded.Verdict)
+
+	g.response.Result = append(g.response.Result, graded)
+	return graded
+}
+
+// Response returns the response built from every result added so far
+func (g *Grader) Response() model.CompileResponse {
+	response := g.response
+	response.Status = "Success"
+	if g.hasError {
+		response.Status = "Error"
+	}
+	response.Verdict = g.verdict
+	if response.Verdict == "" {
+		response.Verdict = model.VerdictAccepted
+	}
+	response.Score = g.problem.Score(g.passed)
+	response.MaxScore = g.problem.ScoreLimit()
+	return response
+}
+
+// BuildResponse compares the judge results with the problem's expected
+// outputs and builds the response returned to the client
+func BuildResponse(problem *model.Problem, response *Response) model.CompileResponse {
+	grader := NewGrader(problem)
+	for i, result := range response.Results {
+		grader.Add(i, result)
+	}
+	for i := len(response.Results); i < len(problem.TestCases); i++ {
+		grader.Missing(i)
+	}
+	return grader.Response()
+}
+
+// Evaluate runs code against every test case of problem and grades the results
+func Evaluate(ctx context.Context, runner Runner, problem *model.Problem, code, language string) (*model.CompileResponse, error) {
+	return EvaluateStream(ctx, runner, problem, code, language, nil)
+}
+
+// EvaluateStream is Evaluate that also passes every graded test case to emit,
+// when not nil, as soon as the judge reports it (see Stream)
+func EvaluateStream(ctx context.Context, runner Runner, problem *model.Problem, code, language string, emit func(result model.CompileResults)) (*model.CompileResponse, error) {
+	problem = LimitsOf(runner).Apply(problem)
+	req, err := BuildRequest(problem, code, language)
+	if err != nil {
+		return nil, err
+	}
+
+	var structuredResponse model.CompileResponse
+	var response *Response
+	if emit == nil {
+		response, err = RunWithDeadline(ctx, runner, req)
+		if err != nil {
+			return nil, err
+		}
+		structuredResponse = BuildResponse(problem, response)
+	} else {
+		grader := NewGrader(problem)
+		response, err = Stream(ctx, runner, req, func(i int, result Result) error {
+			emit(grader.Add(i, result))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		structuredResponse = grader.Response()
+	}
+
+	log.Printf("Compile service response: %+v", response)
+
+	structuredResponse.Diagnostics = response.Diagnostics
+	return &structuredResponse, nil
+}
+
+// EvaluateWhenAvailable is Evaluate for background work: while the judge's
+// circuit breaker is open it waits for the judge to recover instead of failing
+func EvaluateWhenAvailable(ctx context.Context, runner Runner, problem *model.Problem, code, language string) (*model.CompileResponse, error) {
+	for {
+		result, err := Evaluate(ctx, runner, problem, code, language)
+
+		var openErr *CircuitOpenError
+		if !errors.As(err, &openErr) {
+			return result, err
+		}
+
+		select {
+		case <-time.After(openErr.RetryAfter):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
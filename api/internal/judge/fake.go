@@ -0,0 +1,71 @@
+package judge
+
+import (
+	"context"
+	model "learning_go/internal/models"
+	"sync/atomic"
+	"time"
+)
+
+// FakeRunner is an in-process Runner for development and tests. It never
+// compiles anything: each test case is answered by Eval, or with a zero
+// output when Eval is nil.
+type FakeRunner struct {
+	// Eval computes the result for one test case
+	Eval func(funName string, args []interface{}) Result
+	// Diagnose answers compile-only requests; no diagnostics when nil
+	Diagnose func(program string) []model.Diagnostic
+	// Delay is slept before answering, to simulate a slow judge
+	Delay time.Duration
+
+	calls atomic.Int64
+}
+
+// NewFakeRunner creates a fake runner that answers every test case with 0
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{}
+}
+
+// Run answers every test case without contacting a judge
+func (f *FakeRunner) Run(ctx context.Context, req *Request) (*Response, error) {
+	return f.Stream(ctx, req, func(int, Result) error { return nil })
+}
+
+// Stream is Run, emitting each test case's result as it is computed
+func (f *FakeRunner) Stream(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	f.calls.Add(1)
+
+	if f.Delay > 0 {
+		select {
+		case <-time.After(f.Delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if req.CompileOnly {
+		response := &Response{Results: []Result{}, Diagnostics: []model.Diagnostic{}}
+		if f.Diagnose != nil {
+			response.Diagnostics = f.Diagnose(req.Program)
+		}
+		return response, nil
+	}
+
+	results := make([]Result, 0, len(req.TestCases))
+	for i, args := range req.TestCases {
+		result := Result{Output: 0}
+		if f.Eval != nil {
+			result = f.Eval(req.FunName, args)
+		}
+		if err := emit(i, result); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return &Response{Results: results}, nil
+}
+
+// Calls returns how many times Run has been invoked
+func (f *FakeRunner) Calls() int64 {
+	return f.calls.Load()
+}
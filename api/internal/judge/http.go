@@ -0,0 +1,157 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+)
+
+// StatusError is returned when the judge answers with a non-200 status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("judge returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// HTTPRunner sends requests to a remote /performTestCases service
+type HTTPRunner struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPRunner creates a runner for the given endpoint. The judge uses a
+// self-signed certificate, so verification is skipped. The runner keeps a
+// pool of connections and is meant to be shared by every request.
+func NewHTTPRunner(url string) *HTTPRunner {
+	tr := &http.Transport{
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+	}
+	return &HTTPRunner{
+		URL:    url,
+		Client: &http.Client{Transport: tr},
+	}
+}
+
+// Run posts the request to the judge and decodes its results
+func (h *HTTPRunner) Run(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := h.post(ctx, req, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return decodeResponse(resp.Body)
+}
+
+// Stream posts the request asking for a streamed response and emits each
+// result as the judge sends it. Judges that do not stream answer with the
+// whole response, whose results are emitted once it has been read.
+func (h *HTTPRunner) Stream(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	resp, err := h.post(ctx, req, StreamContentType+", application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != StreamContentType {
+		response, err := decodeResponse(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		for i, result := range response.Results {
+			if err := emit(i, result); err != nil {
+				return nil, err
+			}
+		}
+		return response, nil
+	}
+
+	response := &Response{Results: []Result{}}
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var line StreamLine
+		if err := decoder.Decode(&line); err == io.EOF {
+			return response, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse judge response: %w", err)
+		}
+
+		if line.Result != nil {
+			if err := emit(len(response.Results), *line.Result); err != nil {
+				return nil, err
+			}
+			response.Results = append(response.Results, *line.Result)
+		}
+		if line.Diagnostics != nil {
+			response.Diagnostics = line.Diagnostics
+		}
+	}
+}
+
+// post sends req to the judge, accepting the given media types. Non-200
+// answers are returned as a *StatusError.
+func (h *HTTPRunner) post(ctx context.Context, req *Request, accept string) (*http.Response, error) {
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal judge request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create judge request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", accept)
+
+	log.Printf("Sending compile request to service: %s", h.URL)
+	log.Printf("Compile request body: %s", reqBytes)
+
+	resp, err := h.Client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("judge unavailable: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read judge response: %w", err)
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	return resp, nil
+}
+
+// decodeResponse reads a whole judge response
+func decodeResponse(body io.Reader) (*Response, error) {
+	respBody, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read judge response: %w", err)
+	}
+
+	var response Response
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse judge response: %w", err)
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,122 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	model "learning_go/internal/models"
+	"time"
+)
+
+// ErrDeadlineExceeded is returned when the judge does not answer before a
+// request's Deadline. Time limits are enforced by the judge, so this is a
+// failure of the judge rather than a verdict on the program.
+var ErrDeadlineExceeded = errors.New("judge did not answer in time")
+
+// timeLimitMessage is the error reported for test cases that ran over their time limit
+const timeLimitMessage = "time limit exceeded"
+
+// Limits are the limits applied to problems that do not set their own
+type Limits struct {
+	TimeLimitMs   int
+	MemoryLimitKB int64
+	// CompileAllowance is the time granted on top of a request's time limits
+	// for compiling the program and reaching the judge
+	CompileAllowance time.Duration
+}
+
+// DefaultLimits returns the limits used when the judge configuration sets none
+func DefaultLimits() Limits {
+	return Limits{
+		TimeLimitMs:      model.DefaultTimeLimitMs,
+		MemoryLimitKB:    model.DefaultMemoryLimitKB,
+		CompileAllowance: 10 * time.Second,
+	}
+}
+
+// LimitsFromEnv returns the default limits overridden by JUDGE_TIME_LIMIT,
+// JUDGE_MEMORY_LIMIT_KB and JUDGE_COMPILE_ALLOWANCE
+func LimitsFromEnv() Limits {
+	limits := DefaultLimits()
+	if limit := envDuration("JUDGE_TIME_LIMIT", 0); limit > 0 {
+		limits.TimeLimitMs = int(limit.Milliseconds())
+	}
+	if limit := envInt("JUDGE_MEMORY_LIMIT_KB", 0); limit > 0 {
+		limits.MemoryLimitKB = int64(limit)
+	}
+	limits.CompileAllowance = envDuration("JUDGE_COMPILE_ALLOWANCE", limits.CompileAllowance)
+	return limits
+}
+
+// LimitsReporter is implemented by runners configured with their own limits
+type LimitsReporter interface {
+	Limits() Limits
+}
+
+// LimitsOf returns the limits runner is configured with, DefaultLimits when
+// it does not report any
+func LimitsOf(runner Runner) Limits {
+	if reporter, ok := runner.(LimitsReporter); ok {
+		return reporter.Limits()
+	}
+	return DefaultLimits()
+}
+
+// Apply returns a copy of problem whose unset limits are replaced by l's.
+// Requests and graders built from the copy enforce the configured limits.
+func (l Limits) Apply(problem *model.Problem) *model.Problem {
+	limited := *problem
+	if limited.TimeLimitMs <= 0 {
+		limited.TimeLimitMs = l.TimeLimitMs
+	}
+	if limited.MemoryLimitKB <= 0 {
+		limited.MemoryLimitKB = l.MemoryLimitKB
+	}
+	return &limited
+}
+
+// Deadline returns how long to wait for the judge to answer req: the time
+// limit of every test case plus the compile allowance. It is zero when req
+// has no time limit.
+func (r *Request) Deadline(allowance time.Duration) time.Duration {
+	if r.TimeLimitMs <= 0 {
+		return 0
+	}
+	cases := max(len(r.TestCases), 1)
+	return allowance + time.Duration(cases)*time.Duration(r.TimeLimitMs)*time.Millisecond
+}
+
+// withDeadline bounds ctx by req's deadline under runner's compile allowance
+func withDeadline(ctx context.Context, runner Runner, req *Request) (context.Context, context.CancelFunc) {
+	if deadline := req.Deadline(LimitsOf(runner).CompileAllowance); deadline > 0 {
+		return context.WithTimeout(ctx, deadline)
+	}
+	return ctx, func() {}
+}
+
+// deadlineError returns ErrDeadlineExceeded when runCtx, derived from ctx by
+// withDeadline, ended because of the request's deadline rather than the
+// caller, nil otherwise
+func deadlineError(ctx, runCtx context.Context) error {
+	if ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrDeadlineExceeded, context.DeadlineExceeded)
+	}
+	return nil
+}
+
+// RunWithDeadline runs req, giving up once its Deadline has passed. Only the
+// judge reports time limit verdicts; a judge that does not answer in time
+// fails with ErrDeadlineExceeded, so the outcome is neither cached nor
+// recorded as the program's verdict.
+func RunWithDeadline(ctx context.Context, runner Runner, req *Request) (*Response, error) {
+	runCtx, cancel := withDeadline(ctx, runner, req)
+	defer cancel()
+
+	response, err := runner.Run(runCtx, req)
+	if err != nil {
+		if deadlineErr := deadlineError(ctx, runCtx); deadlineErr != nil {
+			return nil, deadlineErr
+		}
+	}
+	return response, err
+}
@@ -0,0 +1,270 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Balancing strategies for Pool
+const (
+	LeastOutstanding = "least-outstanding"
+	RoundRobin       = "round-robin"
+)
+
+// NodeHealth reports the state and counters of one judge instance
+type NodeHealth struct {
+	URL          string          `json:"url"`
+	Healthy      bool            `json:"healthy"`
+	Breaker      BreakerSnapshot `json:"breaker"`
+	Outstanding  int64           `json:"outstanding"`
+	Requests     uint64          `json:"requests"`
+	Failures     uint64          `json:"failures"`
+	AvgLatencyMs float64         `json:"avgLatencyMs"`
+}
+
+// poolNode is one judge instance with its own circuit breaker and counters
+type poolNode struct {
+	url     string
+	runner  Runner
+	breaker *Breaker
+
+	outstanding atomic.Int64
+	requests    atomic.Uint64
+	failures    atomic.Uint64
+	latencyNs   atomic.Int64
+}
+
+// Pool spreads requests over several judge instances. Each node has its own
+// circuit breaker, so failing nodes are ejected until they recover, and a
+// request that fails on one node is retried on the next. Failing over is the
+// pool's only retry: every node is tried at most once, without backoff.
+type Pool struct {
+	nodes    []*poolNode
+	strategy string
+	timeout  time.Duration
+	next     atomic.Uint64
+	// HealthInterval is how often StartHealthChecks probes every node
+	HealthInterval time.Duration
+}
+
+// NewPool creates an empty pool balancing with strategy, which defaults to
+// LeastOutstanding. Each attempt on a node is bounded by timeout.
+func NewPool(strategy string, timeout time.Duration) *Pool {
+	if strategy != RoundRobin {
+		strategy = LeastOutstanding
+	}
+	return &Pool{strategy: strategy, timeout: timeout, HealthInterval: 10 * time.Second}
+}
+
+// AddNode adds a judge instance, identified by url in metrics, to the pool
+func (p *Pool) AddNode(url string, runner Runner, breaker *Breaker) {
+	p.nodes = append(p.nodes, &poolNode{url: url, runner: runner, breaker: breaker})
+}
+
+// Run sends req to the best available node, failing over to the others on
+// transient errors
+func (p *Pool) Run(ctx context.Context, req *Request) (*Response, error) {
+	return p.run(ctx, req, nil)
+}
+
+// Stream streams req from the best available node. It fails over to the
+// others until the first result has been emitted.
+func (p *Pool) Stream(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	return p.run(ctx, req, emit)
+}
+
+// run sends req to the nodes in turn, streaming the results to emit when not nil
+func (p *Pool) run(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	emitted := false
+	if emit != nil {
+		callerEmit := emit
+		emit = func(index int, result Result) error {
+			emitted = true
+			return callerEmit(index, result)
+		}
+	}
+
+	if len(p.nodes) == 0 {
+		return nil, errors.New("judge pool has no nodes")
+	}
+
+	tried := make(map[*poolNode]bool, len(p.nodes))
+	var lastErr error
+
+	for len(tried) < len(p.nodes) {
+		node, retryAfter := p.pick(tried)
+		if node == nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, &CircuitOpenError{RetryAfter: retryAfter}
+		}
+		tried[node] = true
+
+		if err := node.breaker.Allow(); err != nil {
+			// Another request took the node's half-open trial
+			lastErr = err
+			continue
+		}
+
+		response, err := p.call(ctx, node, req, emit)
+		var emitErr *emitError
+		switch {
+		case err == nil:
+			node.breaker.Success()
+			return response, nil
+		case errors.As(err, &emitErr):
+			// The results could not be delivered; the node is fine
+			node.breaker.Release()
+			return nil, err
+		case ctx.Err() != nil:
+			// The caller gave up or ran out of its time budget, which code
+			// that never terminates does on a healthy node. The pool's own
+			// per-attempt timeout is counted below.
+			node.breaker.Release()
+			return nil, ctx.Err()
+		case !IsTransient(err):
+			node.breaker.Success()
+			return nil, err
+		}
+
+		log.Printf("Judge node %s failed: %v", node.url, err)
+		node.failures.Add(1)
+		node.breaker.Failure()
+		lastErr = err
+		if emitted {
+			// Another node would emit the same results again
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// call runs req on node, tracking outstanding requests and latency
+func (p *Pool) call(ctx context.Context, node *poolNode, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	node.outstanding.Add(1)
+	node.requests.Add(1)
+	start := time.Now()
+	defer func() {
+		node.outstanding.Add(-1)
+		node.latencyNs.Add(int64(time.Since(start)))
+	}()
+
+	if emit == nil {
+		return node.runner.Run(ctx, req)
+	}
+	return stream(ctx, node.runner, req, emit)
+}
+
+// pick chooses an available node that has not been tried yet. When every
+// node is ejected it returns nil and the shortest time until one may recover.
+func (p *Pool) pick(tried map[*poolNode]bool) (*poolNode, time.Duration) {
+	start := int(p.next.Add(1) % uint64(len(p.nodes)))
+
+	var best *poolNode
+	var retryAfter time.Duration
+	for i := range p.nodes {
+		node := p.nodes[(start+i)%len(p.nodes)]
+		if tried[node] {
+			continue
+		}
+		if ok, wait := node.breaker.Available(); !ok {
+			if retryAfter == 0 || wait < retryAfter {
+				retryAfter = wait
+			}
+			continue
+		}
+		if p.strategy == RoundRobin {
+			return node, 0
+		}
+		if best == nil || node.outstanding.Load() < best.outstanding.Load() {
+			best = node
+		}
+	}
+	return best, retryAfter
+}
+
+// StartHealthChecks probes every node each HealthInterval until ctx is done.
+// Only a well-formed answer to the empty probe counts as healthy; an ejected
+// node is probed once its breaker allows a trial call.
+func (p *Pool) StartHealthChecks(ctx context.Context) {
+	if p.HealthInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(p.HealthInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.probe(ctx)
+			}
+		}
+	}()
+}
+
+func (p *Pool) probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, node := range p.nodes {
+		wg.Add(1)
+		go func(node *poolNode) {
+			defer wg.Done()
+			if err := node.breaker.Allow(); err != nil {
+				// Still cooling down, or a request holds the half-open trial
+				return
+			}
+			probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			req := &Request{TestCases: [][]interface{}{}}
+			response, err := node.runner.Run(probeCtx, req)
+			switch {
+			case ctx.Err() != nil:
+				node.breaker.Release()
+			case err == nil && response != nil && len(response.Results) == len(req.TestCases):
+				node.breaker.Success()
+			case err != nil && IsTransient(err):
+				node.breaker.Failure()
+			default:
+				// The node answered, but not like a working judge; leave the
+				// breaker to the next request
+				node.breaker.Release()
+			}
+		}(node)
+	}
+	wg.Wait()
+}
+
+// Health reports every node; the pool is healthy while any node is
+func (p *Pool) Health() Health {
+	health := Health{}
+	for _, node := range p.nodes {
+		snapshot := node.breaker.Snapshot()
+		nodeHealth := NodeHealth{
+			URL:         node.url,
+			Healthy:     snapshot.State == BreakerClosed,
+			Breaker:     snapshot,
+			Outstanding: node.outstanding.Load(),
+			Requests:    node.requests.Load(),
+			Failures:    node.failures.Load(),
+		}
+		if nodeHealth.Requests > 0 {
+			nodeHealth.AvgLatencyMs = float64(node.latencyNs.Load()) / float64(nodeHealth.Requests) / float64(time.Millisecond)
+		}
+		health.Healthy = health.Healthy || nodeHealth.Healthy
+		health.Nodes = append(health.Nodes, nodeHealth)
+	}
+	return health
+}
@@ -0,0 +1,151 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	model "learning_go/internal/models"
+)
+
+// ErrNoReferenceSolution is returned when checking a problem that has no
+// reference solution
+var ErrNoReferenceSolution = errors.New("problem has no reference solution")
+
+// Reference check statuses of a single test case
+const (
+	// ReferenceMatch means the reference output equals the stored expected output
+	ReferenceMatch = "match"
+	// ReferenceMismatch means the reference output differs from the stored expected output
+	ReferenceMismatch = "mismatch"
+	// ReferenceMissing means the test case has no expected output yet
+	ReferenceMissing = "missing"
+	// ReferenceError means the reference solution failed on the test case
+	ReferenceError = "error"
+)
+
+// ReferenceCase is the outcome of running the reference solution on one test case
+type ReferenceCase struct {
+	Index     int           `json:"index"`
+	Input     []interface{} `json:"input,omitempty"`
+	Expected  interface{}   `json:"expected,omitempty"`
+	Generated interface{}   `json:"generated,omitempty"`
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// ReferenceReport compares the reference solution's outputs with a
+// problem's expected outputs
+type ReferenceReport struct {
+	Cases      []ReferenceCase `json:"cases"`
+	Matched    int             `json:"matched"`
+	Mismatched int             `json:"mismatched"`
+	Missing    int             `json:"missing"`
+	Errors     int             `json:"errors"`
+	// OK is true when every test case matches the reference solution
+	OK bool `json:"ok"`
+}
+
+// CheckReference runs a problem's reference solution on every test case
+// input and compares its outputs with the stored expected outputs
+func CheckReference(ctx context.Context, runner Runner, problem *model.Problem) (*ReferenceReport, error) {
+	if problem.ReferenceSolution == "" {
+		return nil, ErrNoReferenceSolution
+	}
+
+	req, err := BuildRequest(LimitsOf(runner).Apply(problem), problem.ReferenceSolution, problem.ReferenceLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := RunWithDeadline(ctx, runner, req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReferenceReport{Cases: make([]ReferenceCase, 0, len(problem.TestCases))}
+	for i := range problem.TestCases {
+		referenceCase := ReferenceCase{Index: i, Input: req.TestCases[i]}
+
+		if i >= len(response.Results) {
+			referenceCase.Status = ReferenceError
+			referenceCase.Error = "no result from the judge"
+			report.add(referenceCase)
+			continue
+		}
+
+		result := response.Results[i]
+		if result.Error != "" {
+			referenceCase.Status = ReferenceError
+			referenceCase.Error = result.Error
+			report.add(referenceCase)
+			continue
+		}
+
+		generated, err := model.ConvertValue(problem.OutputType(), result.Output)
+		if err != nil {
+			referenceCase.Status = ReferenceError
+			referenceCase.Error = fmt.Sprintf("invalid output: %v", err)
+			report.add(referenceCase)
+			continue
+		}
+		referenceCase.Generated = generated
+
+		if !problem.TestCases[i].HasExpected() {
+			referenceCase.Status = ReferenceMissing
+			report.add(referenceCase)
+			continue
+		}
+
+		expected, err := problem.ExpectedOutput(i)
+		if err != nil {
+			referenceCase.Status = ReferenceMismatch
+			referenceCase.Error = err.Error()
+		} else {
+			referenceCase.Expected = expected
+			referenceCase.Status = ReferenceMismatch
+			if problem.CompareOutput(expected, generated) {
+				referenceCase.Status = ReferenceMatch
+			}
+		}
+		report.add(referenceCase)
+	}
+
+	report.OK = report.Matched == len(problem.TestCases)
+	return report, nil
+}
+
+// add records a test case outcome and counts its status
+func (r *ReferenceReport) add(referenceCase ReferenceCase) {
+	switch referenceCase.Status {
+	case ReferenceMatch:
+		r.Matched++
+	case ReferenceMismatch:
+		r.Mismatched++
+	case ReferenceMissing:
+		r.Missing++
+	case ReferenceError:
+		r.Errors++
+	}
+	r.Cases = append(r.Cases, referenceCase)
+}
+
+// Apply writes the generated outputs into problem's test cases: missing
+// expected outputs are always filled, mismatched ones only when overwrite
+// is set. It returns the number of test cases changed.
+func (r *ReferenceReport) Apply(problem *model.Problem, overwrite bool) int {
+	updated := 0
+	for _, referenceCase := range r.Cases {
+		if referenceCase.Index >= len(problem.TestCases) {
+			continue
+		}
+		if referenceCase.Status != ReferenceMissing && (referenceCase.Status != ReferenceMismatch || !overwrite) {
+			continue
+		}
+
+		testCase := &problem.TestCases[referenceCase.Index]
+		testCase.Expected = referenceCase.Generated
+		testCase.Output = ""
+		updated++
+	}
+	return updated
+}
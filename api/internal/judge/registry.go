@@ -0,0 +1,120 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	model "learning_go/internal/models"
+	"sort"
+)
+
+// ErrUnsupportedLanguage is returned for requests in a language that has no
+// registered runner
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// LanguageSupporter is implemented by runners that only accept some
+// languages. Runners that do not implement it accept every language.
+type LanguageSupporter interface {
+	Supports(language string) bool
+}
+
+// SupportsLanguage reports whether runner accepts requests in language
+func SupportsLanguage(runner Runner, language string) bool {
+	if supporter, ok := runner.(LanguageSupporter); ok {
+		return supporter.Supports(language)
+	}
+	return true
+}
+
+// Registry routes each request to the runner registered for its language.
+// Requests without a language go to model.DefaultLanguage.
+type Registry struct {
+	runners map[string]Runner
+	limits  Limits
+}
+
+// NewRegistry creates an empty registry enforcing DefaultLimits
+func NewRegistry() *Registry {
+	return &Registry{runners: make(map[string]Runner), limits: DefaultLimits()}
+}
+
+// SetLimits sets the limits of problems that do not set their own. It is
+// not safe to call once the registry is serving requests.
+func (r *Registry) SetLimits(limits Limits) {
+	r.limits = limits
+}
+
+// Limits returns the limits of problems that do not set their own
+func (r *Registry) Limits() Limits {
+	return r.limits
+}
+
+// Register sets the runner judging language. It is not safe to call once the
+// registry is serving requests.
+func (r *Registry) Register(language string, runner Runner) {
+	r.runners[model.NormalizeLanguage(language)] = runner
+}
+
+// Supports reports whether a runner is registered for language
+func (r *Registry) Supports(language string) bool {
+	_, ok := r.runners[model.NormalizeLanguage(language)]
+	return ok
+}
+
+// Languages returns the registered languages, sorted
+func (r *Registry) Languages() []string {
+	languages := make([]string, 0, len(r.runners))
+	for language := range r.runners {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Run sends req to the runner registered for req.Language
+func (r *Registry) Run(ctx context.Context, req *Request) (*Response, error) {
+	language := model.NormalizeLanguage(req.Language)
+	runner, ok := r.runners[language]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedLanguage, language)
+	}
+	return runner.Run(ctx, req)
+}
+
+// Stream streams req from the runner registered for req.Language
+func (r *Registry) Stream(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	language := model.NormalizeLanguage(req.Language)
+	runner, ok := r.runners[language]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedLanguage, language)
+	}
+	return stream(ctx, runner, req, emit)
+}
+
+// Health reports every language's judge; the registry is healthy while all are
+func (r *Registry) Health() Health {
+	health := Health{Healthy: true, Languages: make(map[string]Health)}
+	for language, runner := range r.runners {
+		reporter, ok := runner.(HealthReporter)
+		if !ok {
+			continue
+		}
+		languageHealth := reporter.Health()
+		health.Languages[language] = languageHealth
+		health.Healthy = health.Healthy && languageHealth.Healthy
+	}
+	return health
+}
+
+// StartHealthChecks starts the background health checks of every judge pool
+// behind runner, until ctx is done
+func StartHealthChecks(ctx context.Context, runner Runner) {
+	switch r := runner.(type) {
+	case *Pool:
+		r.StartHealthChecks(ctx)
+	case *Registry:
+		for _, languageRunner := range r.runners {
+			StartHealthChecks(ctx, languageRunner)
+		}
+	}
+}
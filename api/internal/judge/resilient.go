@@ -0,0 +1,184 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Health describes a judge backend for health checks
+type Health struct {
+	Healthy bool             `json:"healthy"`
+	Breaker *BreakerSnapshot `json:"breaker,omitempty"`
+	// Nodes is set for pools of judge instances
+	Nodes []NodeHealth `json:"nodes,omitempty"`
+	// Languages is set for registries, keyed by language
+	Languages map[string]Health `json:"languages,omitempty"`
+}
+
+// HealthReporter is implemented by runners that can report their health
+type HealthReporter interface {
+	Health() Health
+}
+
+// Retry limits of ResilientRunner
+const (
+	// MaxBackoff caps the delay before a retry
+	MaxBackoff = 10 * time.Second
+	// MaxRetries caps JUDGE_MAX_RETRIES
+	MaxRetries = 10
+)
+
+// ResilientRunner wraps a Runner with per-attempt deadlines, bounded retries
+// with jittered exponential backoff, and a circuit breaker
+type ResilientRunner struct {
+	Runner Runner
+	// Timeout bounds each attempt, on top of the caller's own deadline
+	Timeout time.Duration
+	// MaxRetries is the number of attempts made after the first one fails
+	MaxRetries int
+	// Backoff is the base delay before the first retry; retries are
+	// immediate when it is zero
+	Backoff time.Duration
+	Breaker *Breaker
+}
+
+// NewResilientRunner wraps runner with the given limits
+func NewResilientRunner(runner Runner, timeout time.Duration, maxRetries int, breaker *Breaker) *ResilientRunner {
+	return &ResilientRunner{
+		Runner:     runner,
+		Timeout:    timeout,
+		MaxRetries: maxRetries,
+		Backoff:    200 * time.Millisecond,
+		Breaker:    breaker,
+	}
+}
+
+// Run calls the wrapped runner, retrying transient failures
+func (rr *ResilientRunner) Run(ctx context.Context, req *Request) (*Response, error) {
+	return rr.run(ctx, req, nil)
+}
+
+// Stream streams req from the wrapped runner. Transient failures are retried
+// until the first result has been emitted; after that a retry would emit
+// results twice.
+func (rr *ResilientRunner) Stream(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	return rr.run(ctx, req, emit)
+}
+
+// run calls the wrapped runner, streaming its results to emit when not nil
+func (rr *ResilientRunner) run(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	emitted := false
+	if emit != nil {
+		callerEmit := emit
+		emit = func(index int, result Result) error {
+			emitted = true
+			return callerEmit(index, result)
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= rr.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if emitted {
+				break
+			}
+			select {
+			case <-time.After(rr.backoff(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			log.Printf("Retrying judge request (attempt %d): %v", attempt+1, lastErr)
+		}
+
+		if err := rr.Breaker.Allow(); err != nil {
+			return nil, err
+		}
+
+		response, err := rr.attempt(ctx, req, emit)
+		var emitErr *emitError
+		switch {
+		case err == nil:
+			rr.Breaker.Success()
+			return response, nil
+		case errors.As(err, &emitErr):
+			// The results could not be delivered; the judge is fine
+			rr.Breaker.Release()
+			return nil, err
+		case ctx.Err() != nil:
+			// The caller gave up, or its deadline passed. RunWithDeadline's
+			// deadline is the submission's own time budget, which code that
+			// never terminates exhausts on a healthy judge, so neither says
+			// anything about the judge. Timeout is counted below.
+			rr.Breaker.Release()
+			return nil, ctx.Err()
+		case !IsTransient(err):
+			// The judge answered, it just rejected the request
+			rr.Breaker.Success()
+			return nil, err
+		}
+
+		rr.Breaker.Failure()
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// backoff returns the delay before retry number attempt. Full jitter: a
+// random duration up to Backoff * 2^attempt, capped at MaxBackoff.
+func (rr *ResilientRunner) backoff(attempt int) time.Duration {
+	if rr.Backoff <= 0 {
+		return 0
+	}
+	ceiling := rr.Backoff
+	for i := 0; i < attempt && ceiling < MaxBackoff; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, MaxBackoff)
+	return time.Duration(rand.Int64N(int64(ceiling)))
+}
+
+// attempt runs a single try bounded by Timeout
+func (rr *ResilientRunner) attempt(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	if rr.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rr.Timeout)
+		defer cancel()
+	}
+	if emit == nil {
+		return rr.Runner.Run(ctx, req)
+	}
+	return stream(ctx, rr.Runner, req, emit)
+}
+
+// Health reports the breaker state
+func (rr *ResilientRunner) Health() Health {
+	snapshot := rr.Breaker.Snapshot()
+	return Health{
+		Healthy: snapshot.State == BreakerClosed,
+		Breaker: &snapshot,
+	}
+}
+
+// IsTransient reports whether err is worth retrying: transport failures,
+// timeouts and 502/503/504 answers. Judging is free of side effects, so
+// repeating a request is always safe.
+func IsTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var openErr *CircuitOpenError
+	if errors.As(err, &openErr) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,225 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	model "learning_go/internal/models"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request is the payload sent to a judge backend. It mirrors the
+// /performTestCases contract: the program, the function to call and one
+// argument list per test case.
+type Request struct {
+	Program   string          `json:"program"`
+	FunName   string          `json:"funName"`
+	TestCases [][]interface{} `json:"testCases"`
+	// Language selects the runner in a Registry; judges ignore it
+	Language string `json:"language,omitempty"`
+	// CompileOnly asks the judge to only compile the program and report
+	// its diagnostics without running any test case
+	CompileOnly bool `json:"compileOnly,omitempty"`
+	// TimeLimitMs bounds each test case's running time and MemoryLimitKB
+	// the program's memory; judges report violations as ErrorTimeout and
+	// ErrorMemory
+	TimeLimitMs   int   `json:"timeLimitMs,omitempty"`
+	MemoryLimitKB int64 `json:"memoryLimitKb,omitempty"`
+}
+
+// Error kinds a judge may report in Result.ErrorType
+const (
+	ErrorCompile = "compile"
+	ErrorRuntime = "runtime"
+	ErrorTimeout = "timeout"
+	ErrorMemory  = "memory"
+)
+
+// Result is the outcome of a single test case as reported by the judge
+type Result struct {
+	Output interface{} `json:"output"`
+	Error  string      `json:"error"`
+	Line   int         `json:"line"`
+	Column int         `json:"column"`
+	// ErrorType classifies Error; judges that omit it are classified by position
+	ErrorType string `json:"errorType,omitempty"`
+	// TimeMs and MemoryKB are reported by judges that measure execution
+	TimeMs   float64 `json:"timeMs,omitempty"`
+	MemoryKB int64   `json:"memoryKb,omitempty"`
+}
+
+// Verdict classifies a failed result. Errors without an ErrorType are
+// compile errors when the judge points at a source position, runtime
+// errors otherwise.
+func (r Result) Verdict() model.Verdict {
+	switch r.ErrorType {
+	case ErrorCompile:
+		return model.VerdictCompileError
+	case ErrorRuntime:
+		return model.VerdictRuntimeError
+	case ErrorTimeout:
+		return model.VerdictTimeLimit
+	case ErrorMemory:
+		return model.VerdictMemoryLimit
+	}
+	if r.Line > 0 || r.Column > 0 {
+		return model.VerdictCompileError
+	}
+	return model.VerdictRuntimeError
+}
+
+// Response holds one Result per test case, in request order
+type Response struct {
+	Results []Result `json:"results"`
+	// Diagnostics lists every compiler message, for judges that report them.
+	// Judges answering a compile-only request send an empty list for clean
+	// code; a missing list means the judge did not compile the program.
+	Diagnostics []model.Diagnostic `json:"diagnostics"`
+}
+
+// Runner executes a program against a list of test inputs
+type Runner interface {
+	Run(ctx context.Context, req *Request) (*Response, error)
+}
+
+// RunnerFunc adapts an ordinary function to the Runner interface
+type RunnerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Run calls f(ctx, req)
+func (f RunnerFunc) Run(ctx context.Context, req *Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+// DefaultURL is the compile service used when JUDGE_URL is not set
+const DefaultURL = "https://172.16.30.3:3001/performTestCases"
+
+// NewFromEnv builds a Registry with one runner per language, of the kind
+// selected by the JUDGE_BACKEND environment variable ("http" or "fake").
+// model.DefaultLanguage is always registered; JUDGE_LANGUAGES lists any other
+// comma-separated languages.
+//
+// The HTTP backend reads the default language's endpoint from JUDGE_URL and
+// wraps it in a ResilientRunner. When JUDGE_URLS lists several comma-separated
+// endpoints it builds a Pool balanced by JUDGE_BALANCER instead. Other
+// languages read JUDGE_URL_<LANGUAGE> and JUDGE_URLS_<LANGUAGE>.
+//
+// The registry enforces the limits read by LimitsFromEnv on problems that do
+// not set their own.
+func NewFromEnv() (Runner, error) {
+	backend := os.Getenv("JUDGE_BACKEND")
+	if backend != "" && backend != "http" && backend != "fake" {
+		return nil, fmt.Errorf("unknown judge backend %q", backend)
+	}
+
+	registry := NewRegistry()
+	registry.SetLimits(LimitsFromEnv())
+	languages := append([]string{model.DefaultLanguage}, splitList(os.Getenv("JUDGE_LANGUAGES"))...)
+	for _, language := range languages {
+		language = model.NormalizeLanguage(language)
+		if registry.Supports(language) {
+			continue
+		}
+		if backend == "fake" {
+			registry.Register(language, NewFakeRunner())
+			continue
+		}
+		runner, err := newHTTPFromEnv(language)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(language, runner)
+	}
+	return registry, nil
+}
+
+// newHTTPFromEnv builds the HTTP runner judging language
+func newHTTPFromEnv(language string) (Runner, error) {
+	suffix := ""
+	if language != model.DefaultLanguage {
+		suffix = "_" + envSuffix(language)
+	}
+
+	if urls := splitList(os.Getenv("JUDGE_URLS" + suffix)); len(urls) > 1 {
+		return newPoolFromEnv(urls), nil
+	} else if len(urls) == 1 {
+		return newResilientFromEnv(NewHTTPRunner(urls[0])), nil
+	}
+
+	url := os.Getenv("JUDGE_URL" + suffix)
+	if url == "" {
+		if language != model.DefaultLanguage {
+			return nil, fmt.Errorf("no judge URL configured for language %q (set JUDGE_URL%s)", language, suffix)
+		}
+		url = DefaultURL
+	}
+	return newResilientFromEnv(NewHTTPRunner(url)), nil
+}
+
+// envSuffix turns a language name into an environment variable suffix,
+// e.g. "c++" into "C__"
+func envSuffix(language string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, language)
+}
+
+// newResilientFromEnv wraps runner using JUDGE_TIMEOUT, JUDGE_MAX_RETRIES
+// (at most MaxRetries), JUDGE_BREAKER_THRESHOLD and JUDGE_BREAKER_COOLDOWN
+func newResilientFromEnv(runner Runner) *ResilientRunner {
+	timeout := envDuration("JUDGE_TIMEOUT", 30*time.Second)
+	maxRetries := min(max(envInt("JUDGE_MAX_RETRIES", 2), 0), MaxRetries)
+	breaker := NewBreaker(
+		envInt("JUDGE_BREAKER_THRESHOLD", 5),
+		envDuration("JUDGE_BREAKER_COOLDOWN", 30*time.Second),
+	)
+	return NewResilientRunner(runner, timeout, maxRetries, breaker)
+}
+
+// newPoolFromEnv builds a pool of HTTP judges using JUDGE_BALANCER,
+// JUDGE_HEALTH_INTERVAL and the per-node JUDGE_TIMEOUT and breaker settings.
+// JUDGE_MAX_RETRIES does not apply: the pool fails over to each node once.
+func newPoolFromEnv(urls []string) *Pool {
+	pool := NewPool(os.Getenv("JUDGE_BALANCER"), envDuration("JUDGE_TIMEOUT", 30*time.Second))
+	pool.HealthInterval = envDuration("JUDGE_HEALTH_INTERVAL", pool.HealthInterval)
+	for _, url := range urls {
+		breaker := NewBreaker(
+			envInt("JUDGE_BREAKER_THRESHOLD", 5),
+			envDuration("JUDGE_BREAKER_COOLDOWN", 30*time.Second),
+		)
+		pool.AddNode(url, NewHTTPRunner(url), breaker)
+	}
+	return pool
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func envInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,98 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	model "learning_go/internal/models"
+)
+
+// StreamContentType is the media type of streamed judge responses: one
+// StreamLine per line of JSON
+const StreamContentType = "application/x-ndjson"
+
+// StreamLine is one line of a streamed judge response. Judges send the
+// result of every test case, in request order, as soon as it has been
+// judged, and may end with the compiler diagnostics.
+type StreamLine struct {
+	Result      *Result            `json:"result,omitempty"`
+	Diagnostics []model.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// Streamer is implemented by runners that can report results as soon as
+// each test case has been judged. Stream calls emit once per test case, in
+// order, and returns the whole response like Run does.
+type Streamer interface {
+	Stream(ctx context.Context, req *Request, emit func(index int, result Result) error) (*Response, error)
+}
+
+// emitError wraps an error returned by emit, such as a client that went
+// away, which says nothing about the judge's health
+type emitError struct {
+	err error
+}
+
+func (e *emitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *emitError) Unwrap() error {
+	return e.err
+}
+
+// Stream runs req and calls emit once per test case, in order. Streamers
+// report each result as soon as it has been judged; other runners are called
+// once for the whole request and their results emitted when it returns. Like
+// RunWithDeadline, it fails with ErrDeadlineExceeded when the judge has not
+// finished by req's Deadline.
+func Stream(ctx context.Context, runner Runner, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	runCtx, cancel := withDeadline(ctx, runner, req)
+	defer cancel()
+
+	response, err := stream(runCtx, runner, req, emit)
+	if err != nil {
+		if deadlineErr := deadlineError(ctx, runCtx); deadlineErr != nil {
+			return nil, deadlineErr
+		}
+	}
+	return response, err
+}
+
+// stream emits the results of req as the runner produces them. Errors
+// returned by emit are wrapped in an *emitError.
+func stream(ctx context.Context, runner Runner, req *Request, emit func(index int, result Result) error) (*Response, error) {
+	checkedEmit := func(index int, result Result) error {
+		if index >= len(req.TestCases) {
+			return fmt.Errorf("judge returned a result for test case %d of %d", index+1, len(req.TestCases))
+		}
+		err := emit(index, result)
+		var emitErr *emitError
+		if err != nil && !errors.As(err, &emitErr) {
+			return &emitError{err: err}
+		}
+		return err
+	}
+
+	var response *Response
+	var err error
+	if streamer, ok := runner.(Streamer); ok {
+		response, err = streamer.Stream(ctx, req, checkedEmit)
+	} else {
+		// A single call compiles the program once, however many test cases there are
+		response, err = runner.Run(ctx, req)
+		if err == nil && len(response.Results) == len(req.TestCases) {
+			for i, result := range response.Results {
+				if err = checkedEmit(i, result); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(req.TestCases) {
+		return nil, fmt.Errorf("judge returned %d results for %d test cases", len(response.Results), len(req.TestCases))
+	}
+	return response, nil
+}
@@ -33,6 +33,11 @@ func (rw *responseWriter) Write(data []byte) (int, error) {
 	return rw.ResponseWriter.Write(data)
 }
 
+// Unwrap exposes the underlying writer so http.ResponseController can flush
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Context key for username
 type contextKey string
 
@@ -112,11 +117,17 @@ func DBLoggingMiddleware(db *mongo.Database) func(http.Handler) http.Handler {
 					var body struct {
 						Code      string `json:"code"`
 						ProblemID string `json:"problemId"`
+						Mode      string `json:"mode"`
+						Language  string `json:"language"`
 					}
 					if err := json.Unmarshal(bodyBytes, &body); err == nil {
 						logEntry.Body = body.Code
-						// Store problemId if this is a compile request
-						if r.URL.Path == "/compile" && body.ProblemID != "" {
+						logEntry.Mode = body.Mode
+						// Store problemId and language if this is a compile or playground request
+						if r.URL.Path == "/compile" || r.URL.Path == "/run" {
+							logEntry.Language = model.NormalizeLanguage(body.Language)
+						}
+						if (r.URL.Path == "/compile" || r.URL.Path == "/run") && body.ProblemID != "" {
 							if problemObjectID, err := primitive.ObjectIDFromHex(body.ProblemID); err == nil {
 								logEntry.Problem = problemObjectID
 							}
@@ -124,8 +135,8 @@ func DBLoggingMiddleware(db *mongo.Database) func(http.Handler) http.Handler {
 					}
 				}
 				logEntry.ResponseStatus = rw.statusCode
-				// Store response body for compile requests
-				if r.URL.Path == "/compile" && len(rw.responseBody) > 0 {
+				// Store response body for compile and playground requests
+				if (r.URL.Path == "/compile" || r.URL.Path == "/run") && len(rw.responseBody) > 0 {
 					logEntry.ResponseBody = string(rw.responseBody)
 				}
 			}
@@ -178,6 +189,29 @@ func AuthenticateMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// AdminMiddleware only lets users with the admin role through. It must run
+// after AuthenticateMiddleware.
+func AdminMiddleware(db *mongo.Database) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, ok := r.Context().Value(usernameKey).(string)
+			if !ok {
+				http.Error(w, "User not authenticated", http.StatusUnauthorized)
+				return
+			}
+
+			userService := model.NewUserService(db)
+			user, err := userService.GetUserByUsername(r.Context(), username)
+			if err != nil || !user.IsAdmin() {
+				http.Error(w, "Admin access required", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func RepeatedRequestMiddleware(db *mongo.Database) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -216,7 +250,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 		w.Header().Set("Access-Control-Expose-Headers", "Authorization")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
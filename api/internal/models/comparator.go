@@ -0,0 +1,176 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Comparator types accepted in Comparator.Type
+const (
+	// CompareExact requires typed values to be identical (the default)
+	CompareExact = "exact"
+	// CompareInt requires both values to be the same integer
+	CompareInt = "int"
+	// CompareString requires both values to be the same string
+	CompareString = "string"
+	// CompareText compares strings ignoring case and runs of whitespace
+	CompareText = "text"
+	// CompareFloat compares numbers, or lists of numbers, within a tolerance
+	CompareFloat = "float"
+	// CompareUnordered compares lists ignoring the order of their elements
+	CompareUnordered = "unordered"
+	// CompareCustom delegates to a Checker registered with RegisterChecker
+	CompareCustom = "custom"
+)
+
+// Comparator decides whether a program's output matches the expected output
+type Comparator struct {
+	Type string `json:"type" bson:"type"`
+	// AbsTolerance and RelTolerance bound the error accepted by CompareFloat;
+	// a value passes if it is within either of them
+	AbsTolerance float64 `json:"abs_tolerance,omitempty" bson:"abs_tolerance,omitempty"`
+	RelTolerance float64 `json:"rel_tolerance,omitempty" bson:"rel_tolerance,omitempty"`
+	// Checker names the registered Checker used by CompareCustom
+	Checker string `json:"checker,omitempty" bson:"checker,omitempty"`
+}
+
+// Checker is a custom comparison between an expected and an actual value,
+// both in the canonical representation of the problem's return type
+type Checker func(expected, actual interface{}) bool
+
+var (
+	checkersMu sync.RWMutex
+	checkers   = map[string]Checker{}
+)
+
+// RegisterChecker makes checker available to problems as Comparator.Checker
+func RegisterChecker(name string, checker Checker) {
+	checkersMu.Lock()
+	defer checkersMu.Unlock()
+	checkers[name] = checker
+}
+
+func lookupChecker(name string) (Checker, bool) {
+	checkersMu.RLock()
+	defer checkersMu.RUnlock()
+	checker, ok := checkers[name]
+	return checker, ok
+}
+
+// Validate checks that the comparator is usable for values of type typ
+func (c *Comparator) Validate(typ string) error {
+	isArray := strings.HasSuffix(typ, "[]")
+	switch c.Type {
+	case "", CompareExact:
+	case CompareInt:
+		if typ != TypeInt {
+			return fmt.Errorf("comparator %q requires return type %q", c.Type, TypeInt)
+		}
+	case CompareString, CompareText:
+		if typ != TypeString {
+			return fmt.Errorf("comparator %q requires return type %q", c.Type, TypeString)
+		}
+	case CompareFloat:
+		if typ != TypeFloat && typ != TypeFloatArray {
+			return fmt.Errorf("comparator %q requires return type %q or %q", c.Type, TypeFloat, TypeFloatArray)
+		}
+		if c.AbsTolerance < 0 || c.RelTolerance < 0 {
+			return fmt.Errorf("comparator tolerances must not be negative")
+		}
+	case CompareUnordered:
+		if !isArray {
+			return fmt.Errorf("comparator %q requires an array return type", c.Type)
+		}
+	case CompareCustom:
+		if _, ok := lookupChecker(c.Checker); !ok {
+			return fmt.Errorf("unknown checker %q", c.Checker)
+		}
+	default:
+		return fmt.Errorf("unknown comparator %q", c.Type)
+	}
+	return nil
+}
+
+// Compare reports whether actual matches expected
+func (c *Comparator) Compare(expected, actual interface{}) bool {
+	switch c.Type {
+	case CompareInt:
+		a, okA := expected.(int)
+		b, okB := actual.(int)
+		return okA && okB && a == b
+	case CompareString:
+		a, okA := expected.(string)
+		b, okB := actual.(string)
+		return okA && okB && a == b
+	case CompareText:
+		a, okA := expected.(string)
+		b, okB := actual.(string)
+		return okA && okB && normalizeText(a) == normalizeText(b)
+	case CompareFloat:
+		return c.floatsEqual(expected, actual)
+	case CompareUnordered:
+		return unorderedEqual(expected, actual)
+	case CompareCustom:
+		checker, ok := lookupChecker(c.Checker)
+		return ok && checker(expected, actual)
+	}
+	return ValuesEqual(expected, actual)
+}
+
+// normalizeText lowercases s and collapses every run of whitespace
+func normalizeText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// floatsEqual compares two numbers, or two lists of numbers element-wise,
+// within the comparator's tolerances
+func (c *Comparator) floatsEqual(expected, actual interface{}) bool {
+	if a, ok := expected.([]interface{}); ok {
+		b, ok := actual.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !c.floatsEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	a, okA := expected.(float64)
+	b, okB := actual.(float64)
+	if !okA || !okB || math.IsNaN(a) || math.IsNaN(b) {
+		return false
+	}
+	diff := math.Abs(a - b)
+	return diff <= c.AbsTolerance || diff <= c.RelTolerance*math.Abs(a)
+}
+
+// unorderedEqual reports whether two lists hold the same elements with the
+// same multiplicity, in any order
+func unorderedEqual(expected, actual interface{}) bool {
+	a, okA := expected.([]interface{})
+	b, okB := actual.([]interface{})
+	if !okA || !okB || len(a) != len(b) {
+		return false
+	}
+
+	used := make([]bool, len(b))
+	for _, want := range a {
+		found := false
+		for j, got := range b {
+			if !used[j] && ValuesEqual(want, got) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -6,31 +6,91 @@ type CompileResponse struct {
 	Status string           `json:"status,omitempty"` // Optional field for status
 	Line   int              `json:"line,omitempty"`   // Optional field for line number
 	Column int              `json:"column,omitempty"` // Optional field for column number
+	Passed int              `json:"passed"`           // Number of test cases that passed
+	Total  int              `json:"total"`            // Number of test cases that were judged
+	// Verdict summarizes the whole submission
+	Verdict Verdict `json:"verdict,omitempty"`
+	// Score is the number of points earned, out of MaxScore
+	Score    float64 `json:"score"`
+	MaxScore float64 `json:"maxScore,omitempty"`
+	// Diagnostics lists every compiler message, when the judge reports them
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Diagnostic is a compiler message attached to a source position, in the
+// shape editors render as squiggles
+type Diagnostic struct {
+	Severity string `json:"severity" bson:"severity"`
+	Line     int    `json:"line" bson:"line"`
+	Column   int    `json:"column" bson:"column"`
+	Message  string `json:"message" bson:"message"`
+}
+
+// DiagnosticsResponse is returned by the compile-only diagnostics endpoint
+type DiagnosticsResponse struct {
+	// Status is "Success" when there are no errors, "Error" otherwise, and
+	// "Unknown" when the judge did not report whether the code compiles
+	Status      string       `json:"status"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
 }
 
 type CompileResults struct {
-	Status         string `json:"status"`
-	Output         []int  `json:"output"`
-	ExpectedOutput []int  `json:"expectedOutput"`
+	Status string `json:"status"`
+	// Verdict is the detailed outcome of the test case
+	Verdict Verdict `json:"verdict,omitempty"`
+	// TimeMs is the execution time reported by the judge, in milliseconds
+	TimeMs float64 `json:"timeMs,omitempty"`
+	// MemoryKB is the peak memory reported by the judge, in kilobytes
+	MemoryKB int64 `json:"memoryKb,omitempty"`
+	// Hidden is set for hidden test cases, whose input and outputs are withheld
+	Hidden bool `json:"hidden,omitempty"`
+	// Input holds the arguments of a sample test case
+	Input []interface{} `json:"input,omitempty"`
+	// Output holds the value returned by the function, typed by Problem.ReturnType
+	Output []interface{} `json:"output,omitempty"`
+	// ExpectedOutput holds the expected value, empty when the case did not run
+	ExpectedOutput []interface{} `json:"expectedOutput,omitempty"`
+}
+
+// RunResponse is returned by the playground: raw outputs for user-supplied
+// inputs, without comparing them to any expected output
+type RunResponse struct {
+	Results []RunResult `json:"results"`
+}
+
+// RunResult is the outcome of running the function on one input tuple
+type RunResult struct {
+	Input  []interface{} `json:"input"`
+	Output interface{}   `json:"output"`
+	Error  string        `json:"error,omitempty"`
+	Line   int           `json:"line,omitempty"`
+	Column int           `json:"column,omitempty"`
 }
 
-func GenerateResponse(output []int, expectedOutput []int) CompileResponse {
+func GenerateResponse(output []interface{}, expectedOutput []interface{}) CompileResponse {
 	var results []CompileResults
 	statusStr := "Success"
 
 	for i, out := range output {
 		var respObj CompileResults
-		if out == expectedOutput[i] {
+		if ValuesEqual(out, expectedOutput[i]) {
 			respObj = CompileResults{
 				Status:         "Success",
-				Output:         []int{out},
-				ExpectedOutput: []int{expectedOutput[i]},
+				Output:         []interface{}{out},
+				ExpectedOutput: []interface{}{expectedOutput[i]},
 			}
 		} else {
 			respObj = CompileResults{
 				Status:         "Failed",
-				Output:         []int{out},
-				ExpectedOutput: []int{expectedOutput[i]},
+				Output:         []interface{}{out},
+				ExpectedOutput: []interface{}{expectedOutput[i]},
 			}
 
 			statusStr = "Error"
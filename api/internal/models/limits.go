@@ -0,0 +1,39 @@
+package model
+
+import "fmt"
+
+// Limits applied to problems that do not set their own. The judge
+// configuration may replace them with its own defaults.
+const (
+	// DefaultTimeLimitMs is the time limit of a single test case, in milliseconds
+	DefaultTimeLimitMs = 2000
+	// DefaultMemoryLimitKB is the memory limit of a program, in kilobytes
+	DefaultMemoryLimitKB int64 = 256 * 1024
+)
+
+// TimeLimit returns the time limit of a single test case, in milliseconds
+func (p *Problem) TimeLimit() int {
+	if p.TimeLimitMs <= 0 {
+		return DefaultTimeLimitMs
+	}
+	return p.TimeLimitMs
+}
+
+// MemoryLimit returns the memory limit of the program, in kilobytes
+func (p *Problem) MemoryLimit() int64 {
+	if p.MemoryLimitKB <= 0 {
+		return DefaultMemoryLimitKB
+	}
+	return p.MemoryLimitKB
+}
+
+// validateLimits rejects negative limits; zero selects the defaults
+func (p *Problem) validateLimits() error {
+	if p.TimeLimitMs < 0 {
+		return fmt.Errorf("time limit must not be negative, got %d", p.TimeLimitMs)
+	}
+	if p.MemoryLimitKB < 0 {
+		return fmt.Errorf("memory limit must not be negative, got %d", p.MemoryLimitKB)
+	}
+	return nil
+}
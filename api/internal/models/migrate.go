@@ -0,0 +1,178 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// submissionsFromLogsMigration names the log backfill in the migrations collection
+const submissionsFromLogsMigration = "submissions_from_logs"
+
+// MigrateSubmissionsFromLogs backfills the submissions collection from the
+// /compile request logs stored before submissions had their own collection.
+// It runs once per database; the completion is recorded in the migrations
+// collection. Re-running it is harmless, as each log is upserted by its ID.
+func MigrateSubmissionsFromLogs(ctx context.Context, db *mongo.Database) (int, error) {
+	migrations := db.Collection("migrations")
+	err := migrations.FindOne(ctx, bson.M{"_id": submissionsFromLogsMigration}).Err()
+	if err == nil {
+		return 0, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+
+	logs := NewLogsService(db).Collection
+	submissions := NewSubmissionService(db).Collection
+	users := NewUserService(db)
+
+	filter := bson.M{
+		"path": "/compile",
+		"mode": bson.M{"$ne": "run"},
+	}
+	cursor, err := logs.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var logEntry Logs
+		if err := cursor.Decode(&logEntry); err != nil {
+			continue // Skip invalid entries
+		}
+
+		submission := submissionFromLog(&logEntry)
+		if submission.Username != "" {
+			if user, err := users.GetUserByUsername(ctx, submission.Username); err == nil {
+				submission.UserID = user.ID
+			}
+		}
+
+		result, err := submissions.UpdateOne(ctx,
+			bson.M{"log_id": logEntry.ID},
+			bson.M{"$setOnInsert": submission},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return migrated, err
+		}
+		if result.UpsertedCount > 0 {
+			migrated++
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return migrated, err
+	}
+
+	_, err = migrations.InsertOne(ctx, bson.M{"_id": submissionsFromLogsMigration, "completed_at": time.Now()})
+	return migrated, err
+}
+
+// submissionFromLog rebuilds a judged submission from a /compile request log
+func submissionFromLog(logEntry *Logs) *Submission {
+	logID := logEntry.ID
+	finishedAt := logEntry.CreatedAt
+	submission := &Submission{
+		ProblemID:  logEntry.Problem,
+		Code:       logEntry.Body,
+		Language:   NormalizeLanguage(logEntry.Language),
+		CreatedAt:  logEntry.CreatedAt.Add(-logEntry.Duration),
+		FinishedAt: &finishedAt,
+		LogID:      &logID,
+	}
+	if logEntry.UserID != nil {
+		submission.Username = *logEntry.UserID
+	}
+
+	var result CompileResponse
+	if logEntry.ResponseBody != "" && json.Unmarshal([]byte(logEntry.ResponseBody), &result) == nil {
+		submission.State = SubmissionDone
+		submission.applyResult(&result)
+	} else {
+		log.Printf("Log %s has no compile response, migrating it as failed", logEntry.ID.Hex())
+		submission.State = SubmissionFailed
+		submission.Error = "No compile response was recorded"
+		submission.Verdict = VerdictInternalError
+	}
+	return submission
+}
+
+// BackfillProblemSlugs names every problem without a slug after its title,
+// so problems created before slugs existed match their exported packages.
+// Problems whose derived slug is already taken are left without one and
+// logged. Re-running it only touches problems still missing a slug.
+func BackfillProblemSlugs(ctx context.Context, db *mongo.Database) (int, error) {
+	problems := NewProblemService(db).Collection
+	cursor, err := problems.Find(ctx, bson.M{"slug": bson.M{"$in": bson.A{nil, ""}}},
+		options.Find().SetProjection(bson.M{"title": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	backfilled := 0
+	for cursor.Next(ctx) {
+		var problem Problem
+		if err := cursor.Decode(&problem); err != nil {
+			return backfilled, err
+		}
+		slug := Slugify(problem.Title)
+		if slug == "" {
+			log.Printf("Problem %s has no title to derive a slug from", problem.ID.Hex())
+			continue
+		}
+
+		_, err := problems.UpdateByID(ctx, problem.ID, bson.M{"$set": bson.M{"slug": slug}})
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("Problem %s cannot take the slug %q, which is already taken", problem.ID.Hex(), slug)
+			continue
+		}
+		if err != nil {
+			return backfilled, err
+		}
+		backfilled++
+	}
+	return backfilled, cursor.Err()
+}
+
+// legacySamplesMigration names the sample backfill in the migrations collection
+const legacySamplesMigration = "legacy_sample_test_cases"
+
+// MarkLegacySamples makes the first test case of every problem without a
+// sample one, so problems stored before test cases could be hidden keep an
+// example and a non-empty "run" mode. Their revision is bumped, as cached
+// responses showed that test case as hidden. It runs once per database, so
+// problems authored later without samples are left alone.
+func MarkLegacySamples(ctx context.Context, db *mongo.Database) (int, error) {
+	migrations := db.Collection("migrations")
+	err := migrations.FindOne(ctx, bson.M{"_id": legacySamplesMigration}).Err()
+	if err == nil {
+		return 0, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+
+	problems := NewProblemService(db).Collection
+	result, err := problems.UpdateMany(ctx,
+		bson.M{
+			"test_cases.0":      bson.M{"$exists": true},
+			"test_cases.sample": bson.M{"$ne": true},
+		},
+		bson.M{"$set": bson.M{"test_cases.0.sample": true, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = migrations.InsertOne(ctx, bson.M{"_id": legacySamplesMigration, "completed_at": time.Now()})
+	return int(result.ModifiedCount), err
+}
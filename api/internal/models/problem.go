@@ -2,6 +2,10 @@ package model
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,35 +14,299 @@ import (
 )
 
 type TestCase struct {
-	Input  string `json:"input" bson:"input"`
-	Output string `json:"output" bson:"output"`
+	// Input is the legacy whitespace-separated argument list
+	Input string `json:"input,omitempty" bson:"input,omitempty"`
+	// Output is the legacy string encoding of the expected output
+	Output string `json:"output,omitempty" bson:"output,omitempty"`
+	// Args holds one value per entry of Problem.Arguments, typed accordingly
+	Args []interface{} `json:"args,omitempty" bson:"args,omitempty"`
+	// Expected is the expected return value, typed by Problem.ReturnType
+	Expected interface{} `json:"expected,omitempty" bson:"expected,omitempty"`
+	// Sample test cases are shown to students; all others are hidden
+	Sample bool `json:"sample" bson:"sample"`
+	// Weight is the share of the problem's score earned by passing, 1 when unset
+	Weight float64 `json:"weight,omitempty" bson:"weight,omitempty"`
+	// Group names a subtask; its test cases only score when all of them pass
+	Group string `json:"group,omitempty" bson:"group,omitempty"`
+}
+
+// HasExpected reports whether the test case stores an expected output
+func (t TestCase) HasExpected() bool {
+	return t.Expected != nil || t.Output != ""
 }
 
 type Problem struct {
 	// A problem has a title, a description (contains examples), a difficulty, hints and several test cases (each test case has an input and an output)
 	ID    primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Title string             `json:"title" bson:"title"`
+	// Slug identifies the problem across databases, e.g. in problem packages
+	Slug string `json:"slug,omitempty" bson:"slug,omitempty"`
 	// Description is a markdown string that contains examples
 	Description string `json:"description" bson:"description"`
 	// Difficulty is an enum that represents the difficulty of the problem
 	Difficulty string `json:"difficulty" bson:"difficulty"`
+	// Tags name the topics practiced by the problem, e.g. "recursion"
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
 	// TestCases is a list of test cases
 	TestCases []TestCase `json:"test_cases" bson:"test_cases"`
 	// Function name for the problem
 	FunctionName string `json:"function_name" bson:"function_name"`
 	// Arguments/parameters for the function
 	Arguments []ParamType `json:"arguments" bson:"arguments"`
+	// ReturnType is the type returned by the function, "int" when empty
+	ReturnType string `json:"return_type,omitempty" bson:"return_type,omitempty"`
+	// Languages lists the languages accepted for the problem, only
+	// DefaultLanguage when empty
+	Languages []string `json:"languages,omitempty" bson:"languages,omitempty"`
+	// ReferenceSolution is a correct program used to generate and verify
+	// expected outputs. It is never shown to students.
+	ReferenceSolution string `json:"reference_solution,omitempty" bson:"reference_solution,omitempty"`
+	// ReferenceLanguage is the language of ReferenceSolution
+	ReferenceLanguage string `json:"reference_language,omitempty" bson:"reference_language,omitempty"`
+	// TimeLimitMs bounds the running time of each test case, in
+	// milliseconds; DefaultTimeLimitMs when unset
+	TimeLimitMs int `json:"time_limit_ms,omitempty" bson:"time_limit_ms,omitempty"`
+	// MemoryLimitKB bounds the memory used by the program, in kilobytes;
+	// DefaultMemoryLimitKB when unset
+	MemoryLimitKB int64 `json:"memory_limit_kb,omitempty" bson:"memory_limit_kb,omitempty"`
+	// MaxScore is the score of a fully solved problem, DefaultMaxScore when unset
+	MaxScore float64 `json:"max_score,omitempty" bson:"max_score,omitempty"`
+	// Comparator decides how outputs are matched, exact equality when nil
+	Comparator *Comparator `json:"comparator,omitempty" bson:"comparator,omitempty"`
 	// CreatedAt is the date and time the problem was created
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	// UpdatedAt is the date and time the problem was last updated
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
 
+// DefaultLanguage is the language of submissions that do not name one
+const DefaultLanguage = "c"
+
+// NormalizeLanguage returns the canonical name of language, DefaultLanguage
+// when it is empty
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return DefaultLanguage
+	}
+	return language
+}
+
+// AllowsLanguage reports whether submissions in language are accepted
+func (p *Problem) AllowsLanguage(language string) bool {
+	language = NormalizeLanguage(language)
+	if len(p.Languages) == 0 {
+		return language == DefaultLanguage
+	}
+	for _, allowed := range p.Languages {
+		if NormalizeLanguage(allowed) == language {
+			return true
+		}
+	}
+	return false
+}
+
 type ParamType struct {
 	Name string `json:"name" bson:"name"`
 	Type string `json:"type" bson:"type"` // e.g., "int", "string", "float"
 }
 
+// OutputType returns the declared return type of the problem's function
+func (p *Problem) OutputType() string {
+	if p.ReturnType == "" {
+		return TypeInt
+	}
+	return p.ReturnType
+}
+
+// CompareOutput reports whether actual matches expected using the
+// problem's comparator. Both values are typed by OutputType.
+func (p *Problem) CompareOutput(expected, actual interface{}) bool {
+	if p.Comparator == nil {
+		return ValuesEqual(expected, actual)
+	}
+	return p.Comparator.Compare(expected, actual)
+}
+
+// ConvertArgs checks that args match Problem.Arguments in number and type,
+// and returns them in their canonical representation
+func (p *Problem) ConvertArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) != len(p.Arguments) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(p.Arguments), len(args))
+	}
+	converted := make([]interface{}, len(args))
+	for j, arg := range args {
+		value, err := ConvertValue(p.Arguments[j].Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", p.Arguments[j].Name, err)
+		}
+		converted[j] = value
+	}
+	return converted, nil
+}
+
+// TestCaseArgs returns the typed arguments of test case i. Legacy test cases
+// are parsed from their whitespace-separated Input.
+func (p *Problem) TestCaseArgs(i int) ([]interface{}, error) {
+	testCase := p.TestCases[i]
+
+	if testCase.Args != nil {
+		args, err := p.ConvertArgs(testCase.Args)
+		if err != nil {
+			return nil, fmt.Errorf("test case %d: %w", i, err)
+		}
+		return args, nil
+	}
+
+	inputs := strings.Fields(testCase.Input)
+	args := make([]interface{}, 0, len(inputs))
+
+	// Problems without declared arguments keep the old guessing behaviour
+	if len(p.Arguments) == 0 {
+		for _, input := range inputs {
+			if val, err := strconv.Atoi(input); err == nil {
+				args = append(args, val)
+			} else {
+				args = append(args, input)
+			}
+		}
+		return args, nil
+	}
+
+	if len(inputs) != len(p.Arguments) {
+		return nil, fmt.Errorf("test case %d: expected %d arguments, got %d", i, len(p.Arguments), len(inputs))
+	}
+	for j, input := range inputs {
+		value, err := ParseValue(p.Arguments[j].Type, input)
+		if err != nil {
+			return nil, fmt.Errorf("test case %d, argument %q: %w", i, p.Arguments[j].Name, err)
+		}
+		args = append(args, value)
+	}
+	return args, nil
+}
+
+// ExpectedOutput returns the typed expected output of test case i
+func (p *Problem) ExpectedOutput(i int) (interface{}, error) {
+	testCase := p.TestCases[i]
+
+	var value interface{}
+	var err error
+	if testCase.Expected != nil {
+		value, err = ConvertValue(p.OutputType(), testCase.Expected)
+	} else {
+		value, err = ParseValue(p.OutputType(), testCase.Output)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("test case %d, expected output: %w", i, err)
+	}
+	return value, nil
+}
+
+// Samples returns a copy of the problem keeping only its sample test cases
+func (p *Problem) Samples() *Problem {
+	samples := *p
+	samples.TestCases = []TestCase{}
+	for _, testCase := range p.TestCases {
+		if testCase.Sample {
+			samples.TestCases = append(samples.TestCases, testCase)
+		}
+	}
+	return &samples
+}
+
+// Public returns the problem as shown to students, with hidden test cases
+// and the reference solution removed
+func (p *Problem) Public() *Problem {
+	public := p.Samples()
+	public.ReferenceSolution = ""
+	public.ReferenceLanguage = ""
+	return public
+}
+
+// Patch returns a copy of the problem with the fields of patch, a JSON
+// object, replaced. Fields are replaced as a whole: test_cases in patch
+// become the problem's test cases rather than being merged into the
+// stored ones.
+func (p *Problem) Patch(patch []byte) (*Problem, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return nil, err
+	}
+
+	current, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(current, &merged); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		merged[name] = value
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	patched := &Problem{}
+	if err := json.Unmarshal(data, patched); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// NormalizeTestCases validates the declared types and every test case, and
+// rewrites legacy string test cases into typed Args and Expected values
+func (p *Problem) NormalizeTestCases() error {
+	for _, arg := range p.Arguments {
+		if !IsKnownType(arg.Type) {
+			return fmt.Errorf("argument %q has unknown type %q", arg.Name, arg.Type)
+		}
+	}
+	if !IsKnownType(p.OutputType()) {
+		return fmt.Errorf("unknown return type %q", p.ReturnType)
+	}
+	if p.Comparator != nil {
+		if err := p.Comparator.Validate(p.OutputType()); err != nil {
+			return err
+		}
+	}
+	if err := p.validateScoring(); err != nil {
+		return err
+	}
+	if err := p.validateLimits(); err != nil {
+		return err
+	}
+
+	for i := range p.TestCases {
+		args, err := p.TestCaseArgs(i)
+		if err != nil {
+			return err
+		}
+		testCase := p.TestCases[i]
+
+		// Expected outputs may be left out when a reference solution
+		// generates them
+		var expected interface{}
+		if testCase.HasExpected() || p.ReferenceSolution == "" {
+			expected, err = p.ExpectedOutput(i)
+			if err != nil {
+				return err
+			}
+		}
+		p.TestCases[i] = TestCase{
+			Args:     args,
+			Expected: expected,
+			Sample:   testCase.Sample,
+			Weight:   testCase.Weight,
+			Group:    testCase.Group,
+		}
+	}
+	return nil
+}
+
 type ProblemService struct {
 	Collection *mongo.Collection
 }
@@ -65,6 +333,151 @@ func (ps *ProblemService) GetProblemByID(ctx context.Context, id string) (*Probl
 	return &problem, nil
 }
 
+// CreateProblem validates a problem and stores it
+func (ps *ProblemService) CreateProblem(ctx context.Context, problem *Problem) error {
+	if err := problem.Validate(); err != nil {
+		return err
+	}
+	if err := ps.checkTags(ctx, problem); err != nil {
+		return err
+	}
+
+	problem.CreatedAt = time.Now()
+	problem.UpdatedAt = problem.CreatedAt
+
+	result, err := ps.Collection.InsertOne(ctx, problem)
+	if err != nil {
+		return err
+	}
+
+	problem.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// checkTags rejects problems assigned to tags that are not managed tags
+func (ps *ProblemService) checkTags(ctx context.Context, problem *Problem) error {
+	unknown, err := NewTagService(ps.Collection.Database()).UnknownTags(ctx, problem.Tags)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: unknown tags %s", ErrInvalidProblem, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// GetProblemBySlug retrieves a problem by its slug
+func (ps *ProblemService) GetProblemBySlug(ctx context.Context, slug string) (*Problem, error) {
+	var problem Problem
+	err := ps.Collection.FindOne(ctx, bson.M{"slug": slug}).Decode(&problem)
+	if err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
+
+// UpsertProblemBySlug replaces the problem with the same slug, or creates it.
+// It reports whether the problem was created.
+func (ps *ProblemService) UpsertProblemBySlug(ctx context.Context, problem *Problem) (bool, error) {
+	if problem.Slug == "" {
+		return false, fmt.Errorf("%w: slug is required", ErrInvalidProblem)
+	}
+
+	current, err := ps.GetProblemBySlug(ctx, problem.Slug)
+	if err == mongo.ErrNoDocuments {
+		return true, ps.CreateProblem(ctx, problem)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	problem.ID = current.ID
+	return false, ps.UpdateProblem(ctx, problem)
+}
+
+// UpdateProblem validates a problem and replaces the stored problem with the
+// same ID, keeping its creation date. The reference solution is kept when
+// problem does not set one, since it is managed separately.
+func (ps *ProblemService) UpdateProblem(ctx context.Context, problem *Problem) error {
+	current, err := ps.GetProblemByID(ctx, problem.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if problem.ReferenceSolution == "" {
+		problem.ReferenceSolution = current.ReferenceSolution
+		problem.ReferenceLanguage = current.ReferenceLanguage
+	}
+	if err := problem.Validate(); err != nil {
+		return err
+	}
+	if err := ps.checkTags(ctx, problem); err != nil {
+		return err
+	}
+
+	problem.CreatedAt = current.CreatedAt
+	problem.UpdatedAt = time.Now()
+
+	result, err := ps.Collection.ReplaceOne(ctx, bson.M{"_id": problem.ID}, problem)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteProblem removes a problem. Its submissions are kept.
+func (ps *ProblemService) DeleteProblem(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		// No problem can have a malformed ID
+		return mongo.ErrNoDocuments
+	}
+
+	result, err := ps.Collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetReferenceSolution stores the reference solution of a problem
+func (ps *ProblemService) SetReferenceSolution(ctx context.Context, id primitive.ObjectID, solution, language string) error {
+	update := bson.M{"$set": bson.M{
+		"reference_solution": solution,
+		"reference_language": NormalizeLanguage(language),
+	}}
+	result, err := ps.Collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdateTestCases validates and stores the test cases of a problem, bumping
+// its revision so cached results are no longer served
+func (ps *ProblemService) UpdateTestCases(ctx context.Context, problem *Problem) error {
+	if err := problem.NormalizeTestCases(); err != nil {
+		return err
+	}
+
+	problem.UpdatedAt = time.Now()
+	update := bson.M{"$set": bson.M{
+		"test_cases": problem.TestCases,
+		"updated_at": problem.UpdatedAt,
+	}}
+	_, err := ps.Collection.UpdateByID(ctx, problem.ID, update)
+	return err
+}
+
 func (ps *ProblemService) GetAllProblems(ctx context.Context) ([]*Problem, error) {
 	cursor, err := ps.Collection.Find(ctx, bson.M{})
 	if err != nil {
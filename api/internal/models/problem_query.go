@@ -0,0 +1,337 @@
+package model
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidQuery is wrapped by the errors returned for malformed problem list queries
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Sort keys accepted in ProblemQuery.Sort
+const (
+	SortTitle      = "title"
+	SortCreated    = "created"
+	SortDifficulty = "difficulty"
+	SortAcceptance = "acceptance"
+	// SortRelevance orders by text search score and requires ProblemQuery.Search
+	SortRelevance = "relevance"
+)
+
+// Page sizes of the problem list
+const (
+	DefaultProblemLimit = 20
+	MaxProblemLimit     = 100
+)
+
+// ProblemQuery selects a page of the problem list
+type ProblemQuery struct {
+	// Difficulties keeps problems with any of the given difficulties
+	Difficulties []string
+	// Tags keeps problems that have every given tag
+	Tags []string
+	// Search is a full-text search over the title and description
+	Search string
+	// Sort is one of the Sort keys; relevance when searching, created otherwise
+	Sort string
+	// Descending reverses the sort order
+	Descending bool
+	// Offset is the number of problems to skip
+	Offset int
+	// Limit is the page size, DefaultProblemLimit when zero
+	Limit int
+}
+
+// Statuses of a problem for the user listing it
+const (
+	ProblemNotAttempted = "not_attempted"
+	ProblemAttempted    = "attempted"
+	ProblemSolved       = "solved"
+)
+
+// ProblemListItem is the summary of a problem shown in the problem list
+type ProblemListItem struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	Title      string             `json:"title" bson:"title"`
+	Difficulty string             `json:"difficulty" bson:"difficulty"`
+	Tags       []string           `json:"tags" bson:"tags"`
+	// AcceptanceRate is the share of judged submissions that were accepted
+	AcceptanceRate float64 `json:"acceptance_rate" bson:"acceptance_rate"`
+	// Status tells whether the listing user solved or attempted the problem
+	Status string `json:"status,omitempty" bson:"status,omitempty"`
+}
+
+// ProblemPage is a page of the problem list
+type ProblemPage struct {
+	Problems []*ProblemListItem `json:"problems"`
+	// Total is the number of problems matching the query, on every page
+	Total int `json:"total"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// EncodeCursor returns the opaque cursor of the page starting at offset
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset encoded by EncodeCursor
+func DecodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	return offset, nil
+}
+
+// Validate checks the query and fills in its defaults
+func (q *ProblemQuery) Validate() error {
+	for _, difficulty := range q.Difficulties {
+		if !IsKnownDifficulty(difficulty) {
+			return fmt.Errorf("%w: unknown difficulty %q", ErrInvalidQuery, difficulty)
+		}
+	}
+
+	q.Tags = NormalizeTags(q.Tags)
+	q.Search = strings.TrimSpace(q.Search)
+	if q.Sort == "" {
+		q.Sort = SortCreated
+		if q.Search != "" {
+			q.Sort = SortRelevance
+		}
+	}
+	switch q.Sort {
+	case SortTitle, SortCreated, SortDifficulty, SortAcceptance:
+	case SortRelevance:
+		if q.Search == "" {
+			return fmt.Errorf("%w: sorting by relevance requires a search", ErrInvalidQuery)
+		}
+	default:
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidQuery, q.Sort)
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultProblemLimit
+	}
+	if q.Limit < 1 || q.Limit > MaxProblemLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxProblemLimit)
+	}
+	if q.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidQuery)
+	}
+	return nil
+}
+
+// filter returns the match stage selecting the queried problems
+func (q *ProblemQuery) filter() bson.M {
+	filter := bson.M{}
+	if q.Search != "" {
+		filter["$text"] = bson.M{"$search": q.Search}
+	}
+	if len(q.Difficulties) > 0 {
+		filter["difficulty"] = bson.M{"$in": q.Difficulties}
+	}
+	if len(q.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": q.Tags}
+	}
+	return filter
+}
+
+// sort returns the sort stage, breaking ties by ID so pages are stable
+func (q *ProblemQuery) sort() bson.D {
+	order := 1
+	if q.Descending {
+		order = -1
+	}
+
+	switch q.Sort {
+	case SortTitle:
+		return bson.D{{Key: "title", Value: order}, {Key: "_id", Value: 1}}
+	case SortDifficulty:
+		return bson.D{{Key: "difficulty_rank", Value: order}, {Key: "_id", Value: 1}}
+	case SortAcceptance:
+		return bson.D{{Key: "acceptance_rate", Value: order}, {Key: "_id", Value: 1}}
+	case SortRelevance:
+		return bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: 1}}
+	}
+	return bson.D{{Key: "created_at", Value: order}, {Key: "_id", Value: order}}
+}
+
+// EnsureIndexes creates the text index used by the problem search, the
+// indexes used by the list filters and the unique index on slugs
+func (ps *ProblemService) EnsureIndexes(ctx context.Context) error {
+	_, err := ps.Collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().SetWeights(bson.M{"title": 10, "description": 1}),
+		},
+		{
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{Keys: bson.D{{Key: "difficulty", Value: 1}}},
+		{Keys: bson.D{{Key: "tags", Value: 1}}},
+	})
+	return err
+}
+
+// userStatusStages returns the stages setting the status of each problem for
+// username from their submissions, and projecting the problem summaries
+func userStatusStages(username string) bson.A {
+	project := bson.M{"title": 1, "difficulty": 1, "tags": 1, "acceptance_rate": 1}
+	if username == "" {
+		return bson.A{bson.M{"$project": project}}
+	}
+
+	project["status"] = bson.M{"$let": bson.M{
+		"vars": bson.M{"history": bson.M{"$first": "$history"}},
+		"in": bson.M{"$switch": bson.M{
+			"branches": bson.A{
+				bson.M{"case": bson.M{"$eq": bson.A{"$$history.solved", true}}, "then": ProblemSolved},
+				bson.M{"case": bson.M{"$gt": bson.A{"$$history.attempts", 0}}, "then": ProblemAttempted},
+			},
+			"default": ProblemNotAttempted,
+		}},
+	}}
+
+	return bson.A{
+		bson.M{"$lookup": bson.M{
+			"from":         "submissions",
+			"localField":   "_id",
+			"foreignField": "problem_id",
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"username": username}},
+				bson.M{"$group": bson.M{
+					"_id":      nil,
+					"attempts": bson.M{"$sum": 1},
+					"solved": bson.M{"$max": bson.M{"$and": bson.A{
+						bson.M{"$eq": bson.A{"$state", SubmissionDone}},
+						bson.M{"$eq": bson.A{"$verdict", VerdictAccepted}},
+					}}},
+				}},
+			},
+			"as": "history",
+		}},
+		bson.M{"$project": project},
+	}
+}
+
+// acceptanceStages returns the stages setting the acceptance rate of each
+// problem, the share of its judged submissions that were accepted
+func acceptanceStages() bson.A {
+	return bson.A{
+		bson.M{"$lookup": bson.M{
+			"from":         "submissions",
+			"localField":   "_id",
+			"foreignField": "problem_id",
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"state": SubmissionDone}},
+				bson.M{"$group": bson.M{
+					"_id":      nil,
+					"total":    bson.M{"$sum": 1},
+					"accepted": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$verdict", VerdictAccepted}}, 1, 0}}},
+				}},
+			},
+			"as": "stats",
+		}},
+		bson.M{"$addFields": bson.M{
+			"acceptance_rate": bson.M{"$let": bson.M{
+				"vars": bson.M{"stats": bson.M{"$first": "$stats"}},
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$gt": bson.A{"$$stats.total", 0}},
+					bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$$stats.accepted", "$$stats.total"}}, 4}},
+					0,
+				}},
+			}},
+		}},
+	}
+}
+
+// ListProblems returns a page of summaries of the problems matching query,
+// with the acceptance rate of each one computed from the judged submissions.
+// When username is set, each summary also tells whether that user solved or
+// attempted the problem.
+func (ps *ProblemService) ListProblems(ctx context.Context, query ProblemQuery, username string) (*ProblemPage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The acceptance rate looks up every submission of a problem, so it is
+	// only computed before paging when the page is sorted by it
+	pipeline := bson.A{
+		bson.M{"$match": query.filter()},
+		bson.M{"$addFields": bson.M{
+			"difficulty_rank": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$eq": bson.A{"$difficulty", DifficultyEasy}}, "then": 0},
+					bson.M{"case": bson.M{"$eq": bson.A{"$difficulty", DifficultyMedium}}, "then": 1},
+					bson.M{"case": bson.M{"$eq": bson.A{"$difficulty", DifficultyHard}}, "then": 2},
+				},
+				"default": 3,
+			}},
+		}},
+	}
+	page := bson.A{
+		bson.M{"$skip": query.Offset},
+		bson.M{"$limit": query.Limit},
+	}
+	if query.Sort == SortAcceptance {
+		pipeline = append(pipeline, acceptanceStages()...)
+	} else {
+		page = append(page, acceptanceStages()...)
+	}
+	pipeline = append(pipeline,
+		bson.M{"$sort": query.sort()},
+		bson.M{"$facet": bson.M{
+			"total":    bson.A{bson.M{"$count": "count"}},
+			"problems": append(page, userStatusStages(username)...),
+		}},
+	)
+
+	cursor, err := ps.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Total    []struct{ Count int } `bson:"total"`
+		Problems []*ProblemListItem    `bson:"problems"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	problems := &ProblemPage{Problems: []*ProblemListItem{}}
+	if len(results) == 0 {
+		return problems, nil
+	}
+	if len(results[0].Total) > 0 {
+		problems.Total = results[0].Total[0].Count
+	}
+	if results[0].Problems != nil {
+		problems.Problems = results[0].Problems
+	}
+	for _, item := range problems.Problems {
+		if item.Tags == nil {
+			item.Tags = []string{}
+		}
+	}
+	if next := query.Offset + len(problems.Problems); next < problems.Total {
+		problems.NextCursor = EncodeCursor(next)
+	}
+	return problems, nil
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Rejudge states
+const (
+	RejudgeRunning = "running"
+	RejudgeDone    = "done"
+	RejudgeFailed  = "failed"
+)
+
+// RejudgeChange records a submission whose outcome changed when rejudged
+type RejudgeChange struct {
+	SubmissionID primitive.ObjectID `json:"submissionId" bson:"submission_id"`
+	Username     string             `json:"username" bson:"username"`
+	OldVerdict   Verdict            `json:"oldVerdict" bson:"old_verdict"`
+	NewVerdict   Verdict            `json:"newVerdict" bson:"new_verdict"`
+	OldScore     float64            `json:"oldScore" bson:"old_score"`
+	NewScore     float64            `json:"newScore" bson:"new_score"`
+}
+
+// RejudgeJob tracks the rejudging of every submission of a problem
+type RejudgeJob struct {
+	// ID is the unique identifier for the job
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// ProblemID is the problem whose submissions are rejudged
+	ProblemID primitive.ObjectID `json:"problemId" bson:"problem_id"`
+	// RequestedBy is the admin who started the job
+	RequestedBy string `json:"requestedBy" bson:"requested_by"`
+	// State is one of running, done or failed
+	State string `json:"state" bson:"state"`
+	// Total, Processed and Failed count submissions; failed ones keep their old result
+	Total     int `json:"total" bson:"total"`
+	Processed int `json:"processed" bson:"processed"`
+	Failed    int `json:"failed" bson:"failed"`
+	// Changes lists the submissions whose verdict or score changed
+	Changes []RejudgeChange `json:"changes" bson:"changes"`
+	// ChangedUsers lists the users with at least one changed submission
+	ChangedUsers []string `json:"changedUsers" bson:"changed_users"`
+	// Error describes why the job failed, set once State is failed
+	Error string `json:"error,omitempty" bson:"error,omitempty"`
+	// StartedAt is the time the job was started
+	StartedAt time.Time `json:"startedAt" bson:"started_at"`
+	// FinishedAt is the time the job reached done or failed
+	FinishedAt *time.Time `json:"finishedAt,omitempty" bson:"finished_at,omitempty"`
+}
+
+// RejudgeService handles rejudge job operations with the database
+type RejudgeService struct {
+	Collection *mongo.Collection
+}
+
+// NewRejudgeService creates a new rejudge service
+func NewRejudgeService(db *mongo.Database) *RejudgeService {
+	return &RejudgeService{
+		Collection: db.Collection("rejudges"),
+	}
+}
+
+// CreateJob stores a new running job
+func (rs *RejudgeService) CreateJob(ctx context.Context, job *RejudgeJob) error {
+	job.State = RejudgeRunning
+	job.StartedAt = time.Now()
+	if job.Changes == nil {
+		job.Changes = []RejudgeChange{}
+	}
+	if job.ChangedUsers == nil {
+		job.ChangedUsers = []string{}
+	}
+
+	result, err := rs.Collection.InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// SaveProgress stores the counters and changes of a job
+func (rs *RejudgeService) SaveProgress(ctx context.Context, job *RejudgeJob) error {
+	update := bson.M{"$set": bson.M{
+		"state":         job.State,
+		"total":         job.Total,
+		"processed":     job.Processed,
+		"failed":        job.Failed,
+		"changes":       job.Changes,
+		"changed_users": job.ChangedUsers,
+		"error":         job.Error,
+		"finished_at":   job.FinishedAt,
+	}}
+	_, err := rs.Collection.UpdateByID(ctx, job.ID, update)
+	return err
+}
+
+// GetJobByID retrieves a rejudge job by its ID
+func (rs *RejudgeService) GetJobByID(ctx context.Context, id string) (*RejudgeJob, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid rejudge ID")
+	}
+
+	var job RejudgeJob
+	err = rs.Collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("rejudge not found")
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
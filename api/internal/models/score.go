@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultMaxScore is the score of a fully solved problem that does not set MaxScore
+const DefaultMaxScore = 100
+
+// ScoreLimit returns the score of a fully solved problem
+func (p *Problem) ScoreLimit() float64 {
+	if p.MaxScore <= 0 {
+		return DefaultMaxScore
+	}
+	return p.MaxScore
+}
+
+// weight returns the weight of test case i, 1 when unset
+func (p *Problem) weight(i int) float64 {
+	if p.TestCases[i].Weight <= 0 {
+		return 1
+	}
+	return p.TestCases[i].Weight
+}
+
+// Score computes the points earned when passed[i] tells whether test case i
+// passed. Each test case is worth its weight; test cases sharing a Group only
+// earn their weights when every one of them passed. The result is scaled to
+// ScoreLimit and rounded to two decimals.
+func (p *Problem) Score(passed []bool) float64 {
+	total := 0.0
+	earned := 0.0
+	groupWeight := map[string]float64{}
+	groupPassed := map[string]bool{}
+
+	for i := range p.TestCases {
+		weight := p.weight(i)
+		total += weight
+		ok := i < len(passed) && passed[i]
+
+		group := p.TestCases[i].Group
+		if group == "" {
+			if ok {
+				earned += weight
+			}
+			continue
+		}
+		if _, seen := groupPassed[group]; !seen {
+			groupPassed[group] = true
+		}
+		groupWeight[group] += weight
+		groupPassed[group] = groupPassed[group] && ok
+	}
+
+	for group, ok := range groupPassed {
+		if ok {
+			earned += groupWeight[group]
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return math.Round(p.ScoreLimit()*earned/total*100) / 100
+}
+
+// EarnedScore returns the response's score. Responses stored before scoring
+// existed are scored as the share of passed test cases out of DefaultMaxScore.
+func (r *CompileResponse) EarnedScore() float64 {
+	if r.MaxScore > 0 {
+		return r.Score
+	}
+	if r.Error != "" || len(r.Result) == 0 {
+		return 0
+	}
+
+	passed := 0
+	for _, result := range r.Result {
+		if result.Status == "Success" {
+			passed++
+		}
+	}
+	return math.Round(DefaultMaxScore*float64(passed)/float64(len(r.Result))*100) / 100
+}
+
+// validateScoring checks the problem's max score and test case weights
+func (p *Problem) validateScoring() error {
+	if p.MaxScore < 0 {
+		return fmt.Errorf("max score must not be negative")
+	}
+	for i, testCase := range p.TestCases {
+		if testCase.Weight < 0 {
+			return fmt.Errorf("test case %d: weight must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// BestScores returns the highest score of solutions for each problem ID
+func BestScores(solutions []*UserSolution) map[string]float64 {
+	best := make(map[string]float64)
+	for _, solution := range solutions {
+		if score, ok := best[solution.ProblemID]; !ok || solution.Score > score {
+			best[solution.ProblemID] = solution.Score
+		}
+	}
+	return best
+}
@@ -0,0 +1,38 @@
+package model
+
+// UserSolution is an entry of a user's solution history
+type UserSolution struct {
+	ID            string  `json:"id"`
+	ProblemID     string  `json:"problemId"`
+	UserID        string  `json:"userId"`
+	Code          string  `json:"code"`
+	Language      string  `json:"language"`
+	Status        string  `json:"status"`
+	Verdict       Verdict `json:"verdict"`
+	Score         float64 `json:"score"`
+	SubmittedAt   string  `json:"submittedAt"`
+	ExecutionTime string  `json:"executionTime"`
+}
+
+// SolutionStatus classifies the response as passed, partial or failed
+func (r *CompileResponse) SolutionStatus() string {
+	// Check if there's a compilation error (syntax error, etc.)
+	if r.Error != "" {
+		return "failed"
+	}
+
+	// Count passed and failed test cases
+	passedCount := 0
+	for _, result := range r.Result {
+		if result.Status == "Success" {
+			passedCount++
+		}
+	}
+
+	if passedCount == 0 {
+		return "failed"
+	} else if passedCount == len(r.Result) {
+		return "passed"
+	}
+	return "partial"
+}
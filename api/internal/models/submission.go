@@ -0,0 +1,346 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Submission states
+const (
+	SubmissionQueued  = "queued"
+	SubmissionRunning = "running"
+	SubmissionDone    = "done"
+	SubmissionFailed  = "failed"
+)
+
+// Submission is a piece of code judged against a problem, either queued
+// through POST /submissions or judged directly by the compile endpoints
+type Submission struct {
+	// ID is the unique identifier for the submission
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// UserID is the ID of the user who submitted the code
+	UserID primitive.ObjectID `json:"userId,omitempty" bson:"user_id,omitempty"`
+	// Username is the user who submitted the code
+	Username string `json:"username" bson:"username"`
+	// ProblemID is the problem the code is judged against
+	ProblemID primitive.ObjectID `json:"problemId" bson:"problem_id"`
+	// Code is the submitted program
+	Code string `json:"code" bson:"code"`
+	// Language is the language Code is written in
+	Language string `json:"language" bson:"language,omitempty"`
+	// State is one of queued, running, done or failed
+	State string `json:"state" bson:"state"`
+	// Result is the judged response, set once State is done
+	Result *CompileResponse `json:"result,omitempty" bson:"result,omitempty"`
+	// Error describes why judging failed, set once State is failed
+	Error string `json:"error,omitempty" bson:"error,omitempty"`
+	// Verdict, Score and MaxScore summarize Result, set once State is done
+	Verdict  Verdict `json:"verdict,omitempty" bson:"verdict,omitempty"`
+	Score    float64 `json:"score" bson:"score"`
+	MaxScore float64 `json:"maxScore,omitempty" bson:"max_score,omitempty"`
+	// JudgeTimeMs and PeakMemoryKB aggregate the per-case measurements of the judge
+	JudgeTimeMs  float64 `json:"judgeTimeMs,omitempty" bson:"judge_time_ms,omitempty"`
+	PeakMemoryKB int64   `json:"peakMemoryKb,omitempty" bson:"peak_memory_kb,omitempty"`
+	// PreviousVerdict is the verdict before the last rejudge
+	PreviousVerdict Verdict `json:"previousVerdict,omitempty" bson:"previous_verdict,omitempty"`
+	// RejudgedAt is the time the submission was last rejudged
+	RejudgedAt *time.Time `json:"rejudgedAt,omitempty" bson:"rejudged_at,omitempty"`
+	// LogID is the request log a migrated submission was built from
+	LogID *primitive.ObjectID `json:"-" bson:"log_id,omitempty"`
+	// CreatedAt is the time the submission was queued
+	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
+	// StartedAt is the time a worker picked up the submission
+	StartedAt *time.Time `json:"startedAt,omitempty" bson:"started_at,omitempty"`
+	// FinishedAt is the time the submission reached done or failed
+	FinishedAt *time.Time `json:"finishedAt,omitempty" bson:"finished_at,omitempty"`
+}
+
+// SubmissionService handles submission operations with the database
+type SubmissionService struct {
+	Collection *mongo.Collection
+}
+
+// NewSubmissionService creates a new submission service
+func NewSubmissionService(db *mongo.Database) *SubmissionService {
+	return &SubmissionService{
+		Collection: db.Collection("submissions"),
+	}
+}
+
+// EnsureIndexes creates the indexes used by the solution history and by
+// the queue, and the unique index that keeps the log migration idempotent
+func (ss *SubmissionService) EnsureIndexes(ctx context.Context) error {
+	_, err := ss.Collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "username", Value: 1}, {Key: "problem_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "problem_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "state", Value: 1}, {Key: "created_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "log_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	})
+	return err
+}
+
+// applyResult sets the result of a judged submission and the summary
+// fields derived from it
+func (s *Submission) applyResult(result *CompileResponse) {
+	s.Result = result
+	s.Verdict = result.OverallVerdict()
+	s.Score = result.EarnedScore()
+	s.MaxScore = result.MaxScore
+	s.JudgeTimeMs = 0
+	s.PeakMemoryKB = 0
+	for _, caseResult := range result.Result {
+		s.JudgeTimeMs += caseResult.TimeMs
+		if caseResult.MemoryKB > s.PeakMemoryKB {
+			s.PeakMemoryKB = caseResult.MemoryKB
+		}
+	}
+}
+
+// RecordSubmission stores a submission that has already been judged
+func (ss *SubmissionService) RecordSubmission(ctx context.Context, submission *Submission, result *CompileResponse) error {
+	submission.State = SubmissionDone
+	submission.applyResult(result)
+	if submission.CreatedAt.IsZero() {
+		submission.CreatedAt = time.Now()
+	}
+	if submission.FinishedAt == nil {
+		now := time.Now()
+		submission.FinishedAt = &now
+	}
+
+	inserted, err := ss.Collection.InsertOne(ctx, submission)
+	if err != nil {
+		return err
+	}
+
+	submission.ID = inserted.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// CreateSubmission stores a new queued submission
+func (ss *SubmissionService) CreateSubmission(ctx context.Context, submission *Submission) error {
+	submission.State = SubmissionQueued
+	submission.CreatedAt = time.Now()
+
+	result, err := ss.Collection.InsertOne(ctx, submission)
+	if err != nil {
+		return err
+	}
+
+	submission.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetSubmissionByID retrieves a submission by its ID
+func (ss *SubmissionService) GetSubmissionByID(ctx context.Context, id string) (*Submission, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid submission ID")
+	}
+
+	var submission Submission
+	err = ss.Collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&submission)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("submission not found")
+		}
+		return nil, err
+	}
+
+	return &submission, nil
+}
+
+// MarkRunning moves a submission to the running state
+func (ss *SubmissionService) MarkRunning(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"state":      SubmissionRunning,
+		"started_at": now,
+	}}
+	_, err := ss.Collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// MarkDone stores the judged result of a submission
+func (ss *SubmissionService) MarkDone(ctx context.Context, id primitive.ObjectID, result *CompileResponse) error {
+	now := time.Now()
+	var judged Submission
+	judged.applyResult(result)
+	update := bson.M{"$set": bson.M{
+		"state":          SubmissionDone,
+		"result":         result,
+		"verdict":        judged.Verdict,
+		"score":          judged.Score,
+		"max_score":      judged.MaxScore,
+		"judge_time_ms":  judged.JudgeTimeMs,
+		"peak_memory_kb": judged.PeakMemoryKB,
+		"finished_at":    now,
+	}}
+	_, err := ss.Collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// Rejudge replaces the result of a submission judged again, keeping its
+// previous verdict
+func (ss *SubmissionService) Rejudge(ctx context.Context, submission *Submission, result *CompileResponse) error {
+	previous := submission.Verdict
+	now := time.Now()
+	submission.applyResult(result)
+	update := bson.M{"$set": bson.M{
+		"state":            SubmissionDone,
+		"result":           result,
+		"error":            "",
+		"verdict":          submission.Verdict,
+		"score":            submission.Score,
+		"max_score":        submission.MaxScore,
+		"judge_time_ms":    submission.JudgeTimeMs,
+		"peak_memory_kb":   submission.PeakMemoryKB,
+		"previous_verdict": previous,
+		"rejudged_at":      now,
+	}}
+	_, err := ss.Collection.UpdateByID(ctx, submission.ID, update)
+	return err
+}
+
+// GetSubmissionIDsByProblem returns the IDs of every finished submission for
+// a problem, oldest first
+func (ss *SubmissionService) GetSubmissionIDsByProblem(ctx context.Context, problemID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	filter := bson.M{
+		"problem_id": problemID,
+		"state":      bson.M{"$in": []string{SubmissionDone, SubmissionFailed}},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetProjection(bson.M{"_id": 1})
+
+	cursor, err := ss.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cursor.Err()
+}
+
+// MarkFailed records that a submission could not be judged
+func (ss *SubmissionService) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string) error {
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"state":       SubmissionFailed,
+		"error":       reason,
+		"finished_at": now,
+	}}
+	_, err := ss.Collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// DeleteSubmission removes a submission
+func (ss *SubmissionService) DeleteSubmission(ctx context.Context, id primitive.ObjectID) error {
+	_, err := ss.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// GetPendingSubmissions returns queued or running submissions, oldest first.
+// Used to resume work that was interrupted by a restart.
+func (ss *SubmissionService) GetPendingSubmissions(ctx context.Context) ([]*Submission, error) {
+	filter := bson.M{"state": bson.M{"$in": []string{SubmissionQueued, SubmissionRunning}}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := ss.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var submissions []*Submission
+	if err = cursor.All(ctx, &submissions); err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// Solution converts the submission into an entry of the user's solution history
+func (s *Submission) Solution() *UserSolution {
+	solution := &UserSolution{
+		ID:          s.ID.Hex(),
+		ProblemID:   s.ProblemID.Hex(),
+		UserID:      s.Username,
+		Code:        s.Code,
+		Language:    NormalizeLanguage(s.Language),
+		Verdict:     s.Verdict,
+		Score:       s.Score,
+		SubmittedAt: s.CreatedAt.Format(time.RFC3339),
+	}
+
+	if s.FinishedAt != nil {
+		solution.ExecutionTime = s.FinishedAt.Sub(s.CreatedAt).String()
+	}
+
+	if s.State == SubmissionDone && s.Result != nil {
+		solution.Status = s.Result.SolutionStatus()
+	} else {
+		solution.Status = "failed"
+		if solution.Verdict == "" {
+			solution.Verdict = VerdictInternalError
+		}
+	}
+	return solution
+}
+
+// findSolutions returns the finished submissions matching filter as
+// solutions, newest first
+func (ss *SubmissionService) findSolutions(ctx context.Context, filter bson.M) ([]*UserSolution, error) {
+	filter["state"] = bson.M{"$in": []string{SubmissionDone, SubmissionFailed}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := ss.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var submissions []*Submission
+	if err = cursor.All(ctx, &submissions); err != nil {
+		return nil, err
+	}
+
+	solutions := make([]*UserSolution, 0, len(submissions))
+	for _, submission := range submissions {
+		solutions = append(solutions, submission.Solution())
+	}
+	return solutions, nil
+}
+
+// GetUserSolutionsByProblem retrieves a user's submissions for a specific problem
+func (ss *SubmissionService) GetUserSolutionsByProblem(ctx context.Context, username, problemID string) ([]*UserSolution, error) {
+	problemObjectID, err := primitive.ObjectIDFromHex(problemID)
+	if err != nil {
+		return nil, err
+	}
+	return ss.findSolutions(ctx, bson.M{"username": username, "problem_id": problemObjectID})
+}
+
+// GetAllUserSolutions retrieves every submission of a user
+func (ss *SubmissionService) GetAllUserSolutions(ctx context.Context, username string) ([]*UserSolution, error) {
+	return ss.findSolutions(ctx, bson.M{"username": username})
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidTag is wrapped by the errors returned for malformed tags
+var ErrInvalidTag = errors.New("invalid tag")
+
+// Tag is a topic problems can be categorized by, e.g. "recursion"
+type Tag struct {
+	// Name identifies the tag and is what problems store in Problem.Tags
+	Name string `json:"name" bson:"_id"`
+	// Description explains the topic
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	// Week is the course week the topic is taught in, 0 when unscheduled
+	Week int `json:"week,omitempty" bson:"week,omitempty"`
+	// CreatedAt is the date and time the tag was created
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// TagCount is a tag with the number of problems assigned to it
+type TagCount struct {
+	Tag      `bson:",inline"`
+	Problems int `json:"problems" bson:"problems"`
+}
+
+// tagPattern matches lowercase, dash-separated tag names
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// NormalizeTag returns the canonical form of a tag name
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeTags normalizes every tag name and drops duplicates
+func NormalizeTags(names []string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, name := range names {
+		name = NormalizeTag(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// Validate normalizes the tag and checks its name and week
+func (t *Tag) Validate() error {
+	t.Name = NormalizeTag(t.Name)
+	if !tagPattern.MatchString(t.Name) {
+		return fmt.Errorf("%w: name %q must be lowercase letters and digits separated by dashes", ErrInvalidTag, t.Name)
+	}
+	if t.Week < 0 {
+		return fmt.Errorf("%w: week must not be negative", ErrInvalidTag)
+	}
+	return nil
+}
+
+type TagService struct {
+	Collection *mongo.Collection
+}
+
+func NewTagService(db *mongo.Database) *TagService {
+	return &TagService{Collection: db.Collection("tags")}
+}
+
+// CreateTag validates and stores a new tag
+func (ts *TagService) CreateTag(ctx context.Context, tag *Tag) error {
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+
+	tag.CreatedAt = time.Now()
+	if _, err := ts.Collection.InsertOne(ctx, tag); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("tag already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteTag removes a tag and unassigns it from every problem
+func (ts *TagService) DeleteTag(ctx context.Context, name string) error {
+	name = NormalizeTag(name)
+	result, err := ts.Collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	// Tags do not affect judging, so the problems keep their revision
+	// (UpdatedAt) and the compile cache entries keyed on it
+	problems := ts.Collection.Database().Collection("problems")
+	_, err = problems.UpdateMany(ctx, bson.M{"tags": name}, bson.M{"$pull": bson.M{"tags": name}})
+	return err
+}
+
+// UnknownTags returns the names that are not managed tags
+func (ts *TagService) UnknownTags(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := ts.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": names}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []Tag
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, tag := range found {
+		known[tag.Name] = true
+	}
+
+	var unknown []string
+	for _, name := range names {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown, nil
+}
+
+// GetTagCounts returns every tag with the number of problems assigned to
+// it, ordered by course week and name
+func (ts *TagService) GetTagCounts(ctx context.Context) ([]*TagCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "problems",
+			"localField":   "_id",
+			"foreignField": "tags",
+			"pipeline":     bson.A{bson.M{"$project": bson.M{"_id": 1}}},
+			"as":           "problems",
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"problems": bson.M{"$size": "$problems"},
+			// Unscheduled tags come after every course week
+			"week_rank": bson.M{"$ifNull": bson.A{"$week", 1 << 30}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "week_rank", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{"week_rank": 0}}},
+	}
+
+	cursor, err := ts.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tags := []*TagCount{}
+	if err := cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Value types accepted in ParamType.Type and Problem.ReturnType
+const (
+	TypeInt         = "int"
+	TypeFloat       = "float"
+	TypeString      = "string"
+	TypeBool        = "bool"
+	TypeIntArray    = "int[]"
+	TypeFloatArray  = "float[]"
+	TypeStringArray = "string[]"
+	TypeBoolArray   = "bool[]"
+)
+
+// IsKnownType reports whether typ is a supported value type
+func IsKnownType(typ string) bool {
+	switch typ {
+	case TypeInt, TypeFloat, TypeString, TypeBool,
+		TypeIntArray, TypeFloatArray, TypeStringArray, TypeBoolArray:
+		return true
+	}
+	return false
+}
+
+// ConvertValue normalizes v, as decoded from JSON or BSON, to the canonical
+// Go representation of typ: int, float64, string, bool or []interface{} of those.
+func ConvertValue(typ string, v interface{}) (interface{}, error) {
+	if elemType, ok := strings.CutSuffix(typ, "[]"); ok {
+		var items []interface{}
+		switch list := v.(type) {
+		case []interface{}:
+			items = list
+		case primitive.A:
+			items = list
+		default:
+			return nil, fmt.Errorf("expected %s, got %T", typ, v)
+		}
+
+		converted := make([]interface{}, len(items))
+		for i, item := range items {
+			value, err := ConvertValue(elemType, item)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			converted[i] = value
+		}
+		return converted, nil
+	}
+
+	switch typ {
+	case TypeInt:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case int32:
+			return int(n), nil
+		case int64:
+			return int(n), nil
+		case float64:
+			if n != math.Trunc(n) {
+				return nil, fmt.Errorf("expected int, got %v", n)
+			}
+			// Converting a float64 outside the int64 range is undefined
+			if n < math.MinInt64 || n >= math.MaxInt64 {
+				return nil, fmt.Errorf("int %v out of range", n)
+			}
+			return int(n), nil
+		case json.Number:
+			i, err := strconv.Atoi(n.String())
+			if err != nil {
+				return nil, fmt.Errorf("expected int, got %v", n)
+			}
+			return i, nil
+		}
+	case TypeFloat:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case int:
+			return float64(n), nil
+		case int32:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case json.Number:
+			f, err := n.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("expected float, got %v", n)
+			}
+			return f, nil
+		}
+	case TypeString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case TypeBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", typ)
+	}
+	return nil, fmt.Errorf("expected %s, got %T", typ, v)
+}
+
+// ParseValue parses a legacy string encoding of a value of type typ. Array
+// elements are separated by commas.
+func ParseValue(typ string, s string) (interface{}, error) {
+	if elemType, ok := strings.CutSuffix(typ, "[]"); ok {
+		items := []interface{}{}
+		if strings.TrimSpace(s) == "" {
+			return items, nil
+		}
+		for _, part := range strings.Split(s, ",") {
+			value, err := ParseValue(elemType, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	}
+
+	switch typ {
+	case TypeInt:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected int, got %q", s)
+		}
+		return i, nil
+	case TypeFloat:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected float, got %q", s)
+		}
+		return f, nil
+	case TypeString:
+		return s, nil
+	case TypeBool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected bool, got %q", s)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
+
+// ValuesEqual reports whether two canonical values are identical
+func ValuesEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
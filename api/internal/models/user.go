@@ -23,12 +23,22 @@ type User struct {
 	Password string `json:"password" bson:"password"`
 	// Email is the email address of the user
 	Email string `json:"email" bson:"email"`
+	// Role is RoleAdmin for users allowed to manage problems, empty otherwise
+	Role string `json:"role,omitempty" bson:"role,omitempty"`
 	// CreatedAt is the timestamp when the user was created
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	// UpdatedAt is the timestamp when the user was last updated
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
 
+// RoleAdmin is the role of users allowed to manage problems and rejudge submissions
+const RoleAdmin = "admin"
+
+// IsAdmin reports whether the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
 // UserService handles user operations with the database
 type UserService struct {
 	Collection *mongo.Collection
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidProblem is wrapped by every error returned by Problem.Validate
+var ErrInvalidProblem = errors.New("invalid problem")
+
+// Difficulties accepted in Problem.Difficulty
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// IsKnownDifficulty reports whether difficulty is one of the accepted difficulties
+func IsKnownDifficulty(difficulty string) bool {
+	switch difficulty {
+	case DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return true
+	}
+	return false
+}
+
+// identifierPattern matches function and argument names valid in every
+// judged language
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsIdentifier reports whether name can be used as a function or argument name
+func IsIdentifier(name string) bool {
+	return identifierPattern.MatchString(name)
+}
+
+// Slugify derives a slug from a problem title, e.g. "Add Two Numbers" into
+// "add-two-numbers"
+func Slugify(title string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return slug.String()
+}
+
+// Validate checks an authored problem before it is stored: a title, a known
+// difficulty, a valid function signature and test cases matching it. Test
+// cases are normalized as by NormalizeTestCases.
+func (p *Problem) Validate() error {
+	if strings.TrimSpace(p.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidProblem)
+	}
+	if p.Slug != "" && !tagPattern.MatchString(p.Slug) {
+		return fmt.Errorf("%w: slug %q must be lowercase letters and digits separated by dashes", ErrInvalidProblem, p.Slug)
+	}
+	if !IsKnownDifficulty(p.Difficulty) {
+		return fmt.Errorf("%w: difficulty must be %q, %q or %q, got %q",
+			ErrInvalidProblem, DifficultyEasy, DifficultyMedium, DifficultyHard, p.Difficulty)
+	}
+	if !IsIdentifier(p.FunctionName) {
+		return fmt.Errorf("%w: function name %q is not a valid identifier", ErrInvalidProblem, p.FunctionName)
+	}
+
+	names := map[string]bool{}
+	for _, arg := range p.Arguments {
+		if !IsIdentifier(arg.Name) {
+			return fmt.Errorf("%w: argument name %q is not a valid identifier", ErrInvalidProblem, arg.Name)
+		}
+		if names[arg.Name] {
+			return fmt.Errorf("%w: argument %q is declared twice", ErrInvalidProblem, arg.Name)
+		}
+		names[arg.Name] = true
+	}
+
+	p.Tags = NormalizeTags(p.Tags)
+
+	if len(p.TestCases) == 0 {
+		return fmt.Errorf("%w: at least one test case is required", ErrInvalidProblem)
+	}
+	// Without declared arguments, legacy inputs would be guessed instead of checked
+	if len(p.Arguments) == 0 {
+		for i, testCase := range p.TestCases {
+			if len(testCase.Args) > 0 || strings.TrimSpace(testCase.Input) != "" {
+				return fmt.Errorf("%w: test case %d: expected 0 arguments", ErrInvalidProblem, i)
+			}
+		}
+	}
+
+	if err := p.NormalizeTestCases(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProblem, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package model
+
+// Verdict is the outcome of judging a test case or a whole submission
+type Verdict string
+
+const (
+	VerdictAccepted      Verdict = "AC"  // Output matches the expected output
+	VerdictWrongAnswer   Verdict = "WA"  // Output differs from the expected output
+	VerdictCompileError  Verdict = "CE"  // Program failed to compile
+	VerdictRuntimeError  Verdict = "RE"  // Program crashed while running
+	VerdictTimeLimit     Verdict = "TLE" // Program exceeded the time limit
+	VerdictMemoryLimit   Verdict = "MLE" // Program exceeded the memory limit
+	VerdictInternalError Verdict = "IE"  // Judge or problem data failed
+)
+
+// verdictPriority orders verdicts when summarizing a submission: a compile
+// error explains every failure, so it wins over per-case verdicts
+var verdictPriority = map[Verdict]int{
+	VerdictCompileError:  0,
+	VerdictInternalError: 1,
+	VerdictTimeLimit:     2,
+	VerdictMemoryLimit:   3,
+	VerdictRuntimeError:  4,
+	VerdictWrongAnswer:   5,
+	VerdictAccepted:      6,
+}
+
+// Worst returns the verdict that best summarizes both a and b
+func (a Verdict) Worst(b Verdict) Verdict {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	if verdictPriority[b] < verdictPriority[a] {
+		return b
+	}
+	return a
+}
+
+// OverallVerdict summarizes the response. Responses stored before verdicts
+// existed are classified from their status fields.
+func (r *CompileResponse) OverallVerdict() Verdict {
+	if r.Verdict != "" {
+		return r.Verdict
+	}
+
+	if r.Error != "" {
+		// Errors without any judged test case come from the API or the judge itself
+		if len(r.Result) == 0 {
+			return VerdictInternalError
+		}
+		if r.Line > 0 || r.Column > 0 {
+			return VerdictCompileError
+		}
+		return VerdictRuntimeError
+	}
+
+	for _, result := range r.Result {
+		if result.Status != "Success" {
+			return VerdictWrongAnswer
+		}
+	}
+	return VerdictAccepted
+}
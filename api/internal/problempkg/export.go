@@ -0,0 +1,231 @@
+package problempkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	model "learning_go/internal/models"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// referenceExtensions maps judged languages to the extension of their
+// reference solution file
+var referenceExtensions = map[string]string{
+	"c":      ".c",
+	"cpp":    ".cpp",
+	"c++":    ".cpp",
+	"java":   ".java",
+	"python": ".py",
+}
+
+// Files returns the files of problem's package, keyed by their slash-separated path
+func Files(problem *model.Problem) (map[string][]byte, error) {
+	files := map[string][]byte{
+		StatementFile: []byte(problem.Description),
+	}
+
+	metadata := Metadata{
+		Slug:          problem.Slug,
+		Title:         problem.Title,
+		Difficulty:    problem.Difficulty,
+		Tags:          problem.Tags,
+		FunctionName:  problem.FunctionName,
+		Arguments:     problem.Arguments,
+		ReturnType:    problem.ReturnType,
+		Languages:     problem.Languages,
+		TimeLimitMs:   problem.TimeLimitMs,
+		MemoryLimitKB: problem.MemoryLimitKB,
+		MaxScore:      problem.MaxScore,
+		Comparator:    problem.Comparator,
+	}
+
+	if problem.ReferenceSolution != "" {
+		language := model.NormalizeLanguage(problem.ReferenceLanguage)
+		extension, ok := referenceExtensions[language]
+		if !ok {
+			extension = ".txt"
+		}
+		metadata.Reference = "solution" + extension
+		metadata.ReferenceLanguage = language
+		files[metadata.Reference] = []byte(problem.ReferenceSolution)
+	}
+
+	width := max(len(fmt.Sprint(len(problem.TestCases))), 2)
+	for i, testCase := range problem.TestCases {
+		name := fmt.Sprintf("%0*d", width, i+1)
+
+		args := testCase.Args
+		if args == nil {
+			args = []interface{}{}
+		}
+		input, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("test %s: %w", name, err)
+		}
+		files[path.Join(TestsDir, name+".in")] = append(input, '\n')
+
+		if testCase.Expected != nil {
+			output, err := json.Marshal(testCase.Expected)
+			if err != nil {
+				return nil, fmt.Errorf("test %s: %w", name, err)
+			}
+			files[path.Join(TestsDir, name+".out")] = append(output, '\n')
+		}
+
+		options := TestOptions{Sample: testCase.Sample, Weight: testCase.Weight, Group: testCase.Group}
+		if options != (TestOptions{}) {
+			if metadata.Tests == nil {
+				metadata.Tests = map[string]TestOptions{}
+			}
+			metadata.Tests[name] = options
+		}
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[MetadataFile] = append(data, '\n')
+	return files, nil
+}
+
+// Write exports problem as a package at dest: a .zip archive when dest ends
+// in .zip, a directory otherwise. Test cases are written in their normalized
+// form, so problems should be validated first.
+func Write(dest string, problem *model.Problem) error {
+	files, err := Files(problem)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(dest, ".zip") {
+		return writeZip(dest, files)
+	}
+
+	// Remove test cases left over from a previous export
+	if err := os.RemoveAll(filepath.Join(dest, TestsDir)); err != nil {
+		return err
+	}
+	for name, data := range files {
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeZip writes files to a zip archive, in a stable order
+func writeZip(dest string, files map[string][]byte) error {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, name := range sortedNames(files) {
+		writer, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(dest, buffer.Bytes(), 0o644)
+}
+
+// Diff lists the differences between a stored problem and a package, both
+// validated so their test cases are normalized. It is empty when importing
+// the package would not change the problem.
+func Diff(stored, pkg *model.Problem) ([]string, error) {
+	storedFiles, err := Files(stored)
+	if err != nil {
+		return nil, err
+	}
+	pkgFiles, err := Files(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var differences []string
+	for _, name := range sortedNames(storedFiles, pkgFiles) {
+		storedData, inStored := storedFiles[name]
+		pkgData, inPkg := pkgFiles[name]
+		switch {
+		case !inPkg:
+			differences = append(differences, name+": only in the database")
+		case !inStored:
+			differences = append(differences, name+": only in the package")
+		case name == MetadataFile:
+			metadataDiff, err := diffMetadata(storedData, pkgData)
+			if err != nil {
+				return nil, err
+			}
+			differences = append(differences, metadataDiff...)
+		case !bytes.Equal(storedData, pkgData):
+			if strings.HasPrefix(name, TestsDir+"/") {
+				differences = append(differences, fmt.Sprintf("%s: %s -> %s",
+					name, bytes.TrimSpace(storedData), bytes.TrimSpace(pkgData)))
+			} else {
+				differences = append(differences, name+": differs")
+			}
+		}
+	}
+	return differences, nil
+}
+
+// diffMetadata lists the problem.json fields that differ
+func diffMetadata(stored, pkg []byte) ([]string, error) {
+	var storedFields, pkgFields map[string]json.RawMessage
+	if err := json.Unmarshal(stored, &storedFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(pkg, &pkgFields); err != nil {
+		return nil, err
+	}
+
+	var differences []string
+	for _, field := range sortedNames(storedFields, pkgFields) {
+		storedValue, pkgValue := compact(storedFields[field]), compact(pkgFields[field])
+		if storedValue != pkgValue {
+			differences = append(differences, fmt.Sprintf("%s: %s: %s -> %s", MetadataFile, field, storedValue, pkgValue))
+		}
+	}
+	return differences, nil
+}
+
+// compact returns value as single-line JSON, "none" when absent
+func compact(value json.RawMessage) string {
+	if value == nil {
+		return "none"
+	}
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, value); err != nil {
+		return string(value)
+	}
+	return buffer.String()
+}
+
+// sortedNames returns the keys of every map, sorted and without duplicates
+func sortedNames[V any](maps ...map[string]V) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range maps {
+		for name := range m {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,223 @@
+// Package problempkg reads and writes problem packages, the portable form of
+// a problem kept in version control. A package is a directory, or a zip
+// archive of one, laid out as:
+//
+//	problem.json     metadata: slug, title, difficulty, tags, function
+//	                 signature, limits and per-test options
+//	statement.md     the problem description, in markdown
+//	tests/NAME.in    the arguments of a test case, as a JSON array
+//	tests/NAME.out   its expected output, as a JSON value
+//	solution.c       optional reference solution, named by problem.json
+//
+// Test cases are ordered by NAME. A test case without an .out file gets its
+// expected output from the reference solution.
+package problempkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	model "learning_go/internal/models"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Names of the files of a package
+const (
+	MetadataFile  = "problem.json"
+	StatementFile = "statement.md"
+	TestsDir      = "tests"
+)
+
+// Metadata is the content of problem.json
+type Metadata struct {
+	Slug          string            `json:"slug"`
+	Title         string            `json:"title"`
+	Difficulty    string            `json:"difficulty"`
+	Tags          []string          `json:"tags,omitempty"`
+	FunctionName  string            `json:"function_name"`
+	Arguments     []model.ParamType `json:"arguments"`
+	ReturnType    string            `json:"return_type,omitempty"`
+	Languages     []string          `json:"languages,omitempty"`
+	TimeLimitMs   int               `json:"time_limit_ms,omitempty"`
+	MemoryLimitKB int64             `json:"memory_limit_kb,omitempty"`
+	MaxScore      float64           `json:"max_score,omitempty"`
+	Comparator    *model.Comparator `json:"comparator,omitempty"`
+	// Reference names the file holding the reference solution
+	Reference         string `json:"reference,omitempty"`
+	ReferenceLanguage string `json:"reference_language,omitempty"`
+	// Tests sets the options of test cases, keyed by their file name without extension
+	Tests map[string]TestOptions `json:"tests,omitempty"`
+}
+
+// TestOptions are the options of a test case that are not stored in its files
+type TestOptions struct {
+	Sample bool    `json:"sample,omitempty"`
+	Weight float64 `json:"weight,omitempty"`
+	Group  string  `json:"group,omitempty"`
+}
+
+// Load reads the package at path, a directory or a .zip archive. The slug
+// defaults to the package's base name.
+func Load(pkgPath string) (*model.Problem, error) {
+	fsys, closer, err := open(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	problem, err := LoadFS(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pkgPath, err)
+	}
+	if problem.Slug == "" {
+		problem.Slug = strings.TrimSuffix(filepath.Base(pkgPath), ".zip")
+	}
+	return problem, nil
+}
+
+// open returns the file system of a package directory or archive. Archives
+// may hold the package at their root or in a single top-level directory.
+func open(pkgPath string) (fs.FS, io.Closer, error) {
+	if !strings.HasSuffix(pkgPath, ".zip") {
+		return os.DirFS(pkgPath), io.NopCloser(nil), nil
+	}
+
+	archive, err := zip.OpenReader(pkgPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := fs.Stat(archive, MetadataFile); err == nil {
+		return archive, archive, nil
+	}
+
+	entries, err := fs.ReadDir(archive, ".")
+	if err == nil && len(entries) == 1 && entries[0].IsDir() {
+		sub, err := fs.Sub(archive, entries[0].Name())
+		if err == nil {
+			return sub, archive, nil
+		}
+	}
+	archive.Close()
+	return nil, nil, fmt.Errorf("%s: no %s found", pkgPath, MetadataFile)
+}
+
+// LoadFS reads a package from the root of fsys
+func LoadFS(fsys fs.FS) (*model.Problem, error) {
+	data, err := fs.ReadFile(fsys, MetadataFile)
+	if err != nil {
+		return nil, err
+	}
+	var metadata Metadata
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("%s: %w", MetadataFile, err)
+	}
+
+	statement, err := fs.ReadFile(fsys, StatementFile)
+	if err != nil {
+		return nil, err
+	}
+
+	problem := &model.Problem{
+		Slug:              metadata.Slug,
+		Title:             metadata.Title,
+		Description:       string(statement),
+		Difficulty:        metadata.Difficulty,
+		Tags:              metadata.Tags,
+		FunctionName:      metadata.FunctionName,
+		Arguments:         metadata.Arguments,
+		ReturnType:        metadata.ReturnType,
+		Languages:         metadata.Languages,
+		TimeLimitMs:       metadata.TimeLimitMs,
+		MemoryLimitKB:     metadata.MemoryLimitKB,
+		MaxScore:          metadata.MaxScore,
+		Comparator:        metadata.Comparator,
+		ReferenceLanguage: metadata.ReferenceLanguage,
+	}
+
+	if metadata.Reference != "" {
+		reference, err := fs.ReadFile(fsys, metadata.Reference)
+		if err != nil {
+			return nil, err
+		}
+		problem.ReferenceSolution = string(reference)
+	}
+
+	names, err := testNames(fsys)
+	if err != nil {
+		return nil, err
+	}
+	for name := range metadata.Tests {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("%s: options for missing test case %q", MetadataFile, name)
+		}
+	}
+
+	for _, name := range names {
+		testCase, err := loadTestCase(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		options := metadata.Tests[name]
+		testCase.Sample = options.Sample
+		testCase.Weight = options.Weight
+		testCase.Group = options.Group
+		problem.TestCases = append(problem.TestCases, testCase)
+	}
+	return problem, nil
+}
+
+// testNames returns the names of the package's test cases, sorted
+func testNames(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, TestsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".in"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// loadTestCase reads the input and, when present, the expected output of a test case
+func loadTestCase(fsys fs.FS, name string) (model.TestCase, error) {
+	var testCase model.TestCase
+
+	input, err := fs.ReadFile(fsys, path.Join(TestsDir, name+".in"))
+	if err != nil {
+		return testCase, err
+	}
+	if err := json.Unmarshal(input, &testCase.Args); err != nil {
+		return testCase, fmt.Errorf("test %s: input must be a JSON array: %w", name, err)
+	}
+	if testCase.Args == nil {
+		testCase.Args = []interface{}{}
+	}
+
+	output, err := fs.ReadFile(fsys, path.Join(TestsDir, name+".out"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return testCase, nil
+	}
+	if err != nil {
+		return testCase, err
+	}
+	if err := json.Unmarshal(output, &testCase.Expected); err != nil {
+		return testCase, fmt.Errorf("test %s: output must be a JSON value: %w", name, err)
+	}
+	return testCase, nil
+}
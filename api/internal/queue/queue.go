@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrQueueFull is returned by Enqueue when no more submissions can be buffered
+var ErrQueueFull = errors.New("submission queue is full")
+
+const (
+	defaultWorkers  = 4
+	defaultCapacity = 500
+	// enqueueTimeout is how long Enqueue waits for a slot in a full buffer
+	enqueueTimeout = 2 * time.Second
+)
+
+// Queue judges submissions in the background with a bounded pool of workers
+type Queue struct {
+	jobs        chan primitive.ObjectID
+	workers     int
+	runner      judge.Runner
+	submissions *model.SubmissionService
+	problems    *model.ProblemService
+	wg          sync.WaitGroup
+}
+
+// New creates a queue with the given number of workers and buffer capacity
+func New(db *mongo.Database, runner judge.Runner, workers, capacity int) *Queue {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if capacity <= 0 {
+		capacity = defaultCapacity
+	}
+	return &Queue{
+		jobs:        make(chan primitive.ObjectID, capacity),
+		workers:     workers,
+		runner:      runner,
+		submissions: model.NewSubmissionService(db),
+		problems:    model.NewProblemService(db),
+	}
+}
+
+// NewFromEnv creates a queue sized by SUBMISSION_WORKERS and SUBMISSION_QUEUE_SIZE
+func NewFromEnv(db *mongo.Database, runner judge.Runner) *Queue {
+	workers, _ := strconv.Atoi(os.Getenv("SUBMISSION_WORKERS"))
+	capacity, _ := strconv.Atoi(os.Getenv("SUBMISSION_QUEUE_SIZE"))
+	return New(db, runner, workers, capacity)
+}
+
+// Start launches the workers and re-queues submissions left pending by a
+// previous run. Workers stop when ctx is cancelled.
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+
+	pending, err := q.submissions.GetPendingSubmissions(ctx)
+	if err != nil {
+		log.Printf("Failed to load pending submissions: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	log.Printf("Resuming %d pending submissions", len(pending))
+	go func() {
+		for _, submission := range pending {
+			select {
+			case q.jobs <- submission.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Wait blocks until every worker has exited
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+// Enqueue stores a new submission and schedules it for judging
+func (q *Queue) Enqueue(ctx context.Context, submission *model.Submission) error {
+	if len(q.jobs) == cap(q.jobs) {
+		return ErrQueueFull
+	}
+
+	if err := q.submissions.CreateSubmission(ctx, submission); err != nil {
+		return err
+	}
+
+	// The buffer may have filled up between the check and the insert; wait
+	// briefly for a slot before giving up
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+	select {
+	case q.jobs <- submission.ID:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	// Remove the submission, or it would run after a restart while the
+	// client retries with a new one
+	if err := q.submissions.DeleteSubmission(context.WithoutCancel(ctx), submission.ID); err != nil {
+		log.Printf("Failed to remove unqueued submission %s: %v", submission.ID.Hex(), err)
+	}
+	return ErrQueueFull
+}
+
+// SupportsLanguage reports whether the queue's judge accepts language
+func (q *Queue) SupportsLanguage(language string) bool {
+	return judge.SupportsLanguage(q.runner, language)
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-q.jobs:
+			q.process(ctx, id)
+		}
+	}
+}
+
+// process judges a single submission and persists its outcome
+func (q *Queue) process(ctx context.Context, id primitive.ObjectID) {
+	submission, err := q.submissions.GetSubmissionByID(ctx, id.Hex())
+	if err != nil {
+		log.Printf("Failed to load submission %s: %v", id.Hex(), err)
+		return
+	}
+
+	if err := q.submissions.MarkRunning(ctx, id); err != nil {
+		log.Printf("Failed to mark submission %s as running: %v", id.Hex(), err)
+	}
+
+	problem, err := q.problems.GetProblemByID(ctx, submission.ProblemID.Hex())
+	if err != nil {
+		q.fail(ctx, id, "Problem not found")
+		return
+	}
+
+	result, err := judge.EvaluateWhenAvailable(ctx, q.runner, problem, submission.Code, submission.Language)
+	if errors.Is(err, judge.ErrInvalidTestCases) {
+		log.Printf("Problem %s has invalid test cases: %v", problem.ID.Hex(), err)
+		q.fail(ctx, id, "Problem has invalid test cases")
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the submission is resumed on the next start
+		return
+	}
+	if err != nil {
+		log.Printf("Judge request failed for submission %s: %v", id.Hex(), err)
+		q.fail(ctx, id, "Compile service unavailable")
+		return
+	}
+
+	if err := q.submissions.MarkDone(ctx, id, result); err != nil {
+		log.Printf("Failed to store result for submission %s: %v", id.Hex(), err)
+	}
+}
+
+func (q *Queue) fail(ctx context.Context, id primitive.ObjectID, reason string) {
+	if err := q.submissions.MarkFailed(ctx, id, reason); err != nil {
+		log.Printf("Failed to mark submission %s as failed: %v", id.Hex(), err)
+	}
+}
@@ -0,0 +1,190 @@
+package rejudge
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/cache"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrAlreadyRunning is returned by Rejudge while the problem is being rejudged
+var ErrAlreadyRunning = errors.New("a rejudge is already running for this problem")
+
+// progressInterval is how many submissions are rejudged between progress saves
+const progressInterval = 10
+
+// Manager rejudges every stored submission of a problem in the background
+type Manager struct {
+	ctx          context.Context
+	runner       judge.Runner
+	compileCache *cache.CompileCache
+	submissions  *model.SubmissionService
+	jobs         *model.RejudgeService
+
+	mu      sync.Mutex
+	running map[primitive.ObjectID]bool
+	wg      sync.WaitGroup
+}
+
+// New creates a rejudge manager
+func New(db *mongo.Database, runner judge.Runner, compileCache *cache.CompileCache) *Manager {
+	return &Manager{
+		ctx:          context.Background(),
+		runner:       runner,
+		compileCache: compileCache,
+		submissions:  model.NewSubmissionService(db),
+		jobs:         model.NewRejudgeService(db),
+		running:      make(map[primitive.ObjectID]bool),
+	}
+}
+
+// Start ties the background jobs to ctx; they stop when it is cancelled
+func (m *Manager) Start(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// Wait blocks until every running job has stopped
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Rejudge invalidates the cached results of problem and starts rejudging its
+// submissions in the background. The returned job is its initial state; its
+// progress is saved to the rejudges collection.
+func (m *Manager) Rejudge(ctx context.Context, problem *model.Problem, requestedBy string) (*model.RejudgeJob, error) {
+	m.mu.Lock()
+	if m.running[problem.ID] {
+		m.mu.Unlock()
+		return nil, ErrAlreadyRunning
+	}
+	m.running[problem.ID] = true
+	m.mu.Unlock()
+
+	if err := m.compileCache.InvalidateProblem(ctx, problem.ID.Hex()); err != nil {
+		log.Printf("Failed to invalidate cached results of problem %s: %v", problem.ID.Hex(), err)
+	}
+
+	job := &model.RejudgeJob{ProblemID: problem.ID, RequestedBy: requestedBy}
+	if err := m.jobs.CreateJob(ctx, job); err != nil {
+		m.done(problem.ID)
+		return nil, err
+	}
+
+	// The job keeps changing in the background; callers get its initial state
+	started := *job
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer m.done(problem.ID)
+		m.run(m.ctx, job, problem)
+	}()
+
+	return &started, nil
+}
+
+func (m *Manager) done(problemID primitive.ObjectID) {
+	m.mu.Lock()
+	delete(m.running, problemID)
+	m.mu.Unlock()
+}
+
+// run rejudges every submission of problem, recording progress in job
+func (m *Manager) run(ctx context.Context, job *model.RejudgeJob, problem *model.Problem) {
+	ids, err := m.submissions.GetSubmissionIDsByProblem(ctx, problem.ID)
+	if err != nil {
+		m.finish(job, "Failed to list submissions")
+		return
+	}
+	job.Total = len(ids)
+	m.save(job)
+
+	changedUsers := map[string]bool{}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			m.finish(job, "Interrupted by shutdown")
+			return
+		}
+
+		submission, err := m.submissions.GetSubmissionByID(ctx, id.Hex())
+		if err != nil {
+			log.Printf("Failed to load submission %s: %v", id.Hex(), err)
+			job.Failed++
+			job.Processed++
+			continue
+		}
+
+		result, err := judge.EvaluateWhenAvailable(ctx, m.runner, problem, submission.Code, submission.Language)
+		if errors.Is(err, judge.ErrInvalidTestCases) {
+			log.Printf("Problem %s has invalid test cases: %v", problem.ID.Hex(), err)
+			m.finish(job, "Problem has invalid test cases")
+			return
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				m.finish(job, "Interrupted by shutdown")
+				return
+			}
+			log.Printf("Failed to rejudge submission %s: %v", id.Hex(), err)
+			job.Failed++
+			job.Processed++
+			continue
+		}
+
+		oldVerdict, oldScore := submission.Verdict, submission.Score
+		if err := m.submissions.Rejudge(ctx, submission, result); err != nil {
+			log.Printf("Failed to store rejudged submission %s: %v", id.Hex(), err)
+			job.Failed++
+			job.Processed++
+			continue
+		}
+
+		if submission.Verdict != oldVerdict || submission.Score != oldScore {
+			job.Changes = append(job.Changes, model.RejudgeChange{
+				SubmissionID: submission.ID,
+				Username:     submission.Username,
+				OldVerdict:   oldVerdict,
+				NewVerdict:   submission.Verdict,
+				OldScore:     oldScore,
+				NewScore:     submission.Score,
+			})
+			if !changedUsers[submission.Username] {
+				changedUsers[submission.Username] = true
+				job.ChangedUsers = append(job.ChangedUsers, submission.Username)
+			}
+		}
+
+		job.Processed++
+		if job.Processed%progressInterval == 0 {
+			m.save(job)
+		}
+	}
+
+	m.finish(job, "")
+}
+
+// finish marks job as done, or failed with reason, and saves it
+func (m *Manager) finish(job *model.RejudgeJob, reason string) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.State = model.RejudgeDone
+	if reason != "" {
+		job.State = model.RejudgeFailed
+		job.Error = reason
+	}
+	m.save(job)
+}
+
+func (m *Manager) save(job *model.RejudgeJob) {
+	// Progress is saved even while shutting down, so the job is not left running
+	if err := m.jobs.SaveProgress(context.WithoutCancel(m.ctx), job); err != nil {
+		log.Printf("Failed to save progress of rejudge %s: %v", job.ID.Hex(), err)
+	}
+}
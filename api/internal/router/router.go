@@ -1,8 +1,12 @@
 package router
 
 import (
+	"learning_go/internal/cache"
 	handler "learning_go/internal/handlers"
+	"learning_go/internal/judge"
 	"learning_go/internal/middleware"
+	"learning_go/internal/queue"
+	"learning_go/internal/rejudge"
 	"net/http"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,8 +20,28 @@ func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.
 	return h
 }
 
-func NewWithDB(db *mongo.Database) http.Handler {
+// Services groups the long-lived dependencies shared by the handlers
+type Services struct {
+	// Runner judges compile requests
+	Runner judge.Runner
+	// Submissions judges queued submissions in the background
+	Submissions *queue.Queue
+	// CompileCache stores judged responses
+	CompileCache *cache.CompileCache
+	// DiagnosticsCache stores compile-only diagnostics, apart from the
+	// verdicts so the editor's frequent requests do not evict them
+	DiagnosticsCache *cache.CompileCache
+	// Rejudges re-runs stored submissions after a problem changes
+	Rejudges *rejudge.Manager
+}
+
+// NewWithDB builds the API routes on top of db and the shared services
+func NewWithDB(db *mongo.Database, services Services) http.Handler {
 	r := http.NewServeMux()
+	runner := services.Runner
+
+	// Health check, including the judge backend state
+	r.Handle("GET /health", handler.GetHealth(runner))
 
 	// Signup route - POST method for user registration
 	r.Handle("POST /signUp", Chain(
@@ -43,12 +67,57 @@ func NewWithDB(db *mongo.Database) http.Handler {
 
 	// POST method for code compilation
 	r.Handle("POST /compile", Chain(
-		handler.GetFullCompile(db),
+		handler.GetFullCompile(db, runner, services.CompileCache),
 		middleware.AuthenticateMiddleware,        // Verifies JWT token
 		middleware.RepeatedRequestMiddleware(db), // Captures request body
 		middleware.DBLoggingMiddleware(db),       // Logs the request
 	))
 
+	// POST method for code compilation, streaming each test case as Server-Sent Events
+	r.Handle("POST /compile/stream", Chain(
+		handler.StreamCompile(db, runner, services.CompileCache),
+		middleware.AuthenticateMiddleware,        // Verifies JWT token
+		middleware.RepeatedRequestMiddleware(db), // Captures request body
+		middleware.DBLoggingMiddleware(db),       // Logs the request
+	))
+
+	// POST method for running code on custom inputs (playground)
+	// Logged under /run, so these never show up as solutions
+	r.Handle("POST /run", Chain(
+		handler.RunCode(db, runner),
+		middleware.AuthenticateMiddleware,        // Verifies JWT token
+		middleware.RepeatedRequestMiddleware(db), // Captures request body
+		middleware.DBLoggingMiddleware(db),       // Logs the request
+	))
+
+	// POST method for compile-only diagnostics, used by the editor for linting
+	// Not logged: the editor calls it on every pause in typing
+	r.Handle("POST /diagnostics", Chain(
+		handler.GetDiagnostics(runner, services.DiagnosticsCache),
+		middleware.AuthenticateMiddleware, // Verifies JWT token
+	))
+
+	// Asynchronous submissions
+	// POST method for queueing code to be judged in the background
+	r.Handle("POST /submissions", Chain(
+		handler.CreateSubmission(db, services.Submissions),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// GET method for polling the state of a submission
+	r.Handle("GET /submissions/{id}", Chain(
+		handler.GetSubmission(db),
+		middleware.AuthenticateMiddleware, // Verifies JWT token
+	))
+
+	// GET method for compile cache hit/miss/eviction counters
+	r.Handle("GET /cache/stats", Chain(
+		handler.GetCompileCacheStats(services.CompileCache),
+		middleware.AuthenticateMiddleware, // Verifies JWT token
+		middleware.AdminMiddleware(db),    // Requires the admin role
+	))
+
 	// Problem routes
 	// GET method for retrieving all problems
 	r.Handle("GET /problems", Chain(
@@ -68,6 +137,94 @@ func NewWithDB(db *mongo.Database) http.Handler {
 		middleware.AuthenticateMiddleware, // Verifies JWT token
 	))
 
+	// Tag routes
+	// GET method for retrieving every tag with its problem count
+	r.Handle("GET /tags", Chain(
+		handler.GetTags(db),
+		middleware.AuthenticateMiddleware, // Verifies JWT token
+	))
+
+	// Admin routes
+	// POST method for creating a tag
+	r.Handle("POST /tags", Chain(
+		handler.CreateTag(db),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// DELETE method for removing a tag from every problem
+	r.Handle("DELETE /tags/{name}", Chain(
+		handler.DeleteTag(db),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// POST method for creating a problem
+	r.Handle("POST /problems", Chain(
+		handler.CreateProblem(db),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// PUT method for replacing a problem
+	r.Handle("PUT /problems/{id}", Chain(
+		handler.UpdateProblem(db),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// PATCH method for changing some fields of a problem
+	r.Handle("PATCH /problems/{id}", Chain(
+		handler.UpdateProblem(db),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// DELETE method for removing a problem
+	r.Handle("DELETE /problems/{id}", Chain(
+		handler.DeleteProblem(db),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// POST method for rejudging every submission of a problem in the background
+	r.Handle("POST /problems/{id}/rejudge", Chain(
+		handler.StartRejudge(db, services.Rejudges),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// PUT method for storing a problem's hidden reference solution
+	r.Handle("PUT /problems/{id}/reference", Chain(
+		handler.SetReferenceSolution(db, runner),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// POST method for verifying, and optionally generating, expected outputs
+	// with the reference solution
+	r.Handle("POST /problems/{id}/reference/check", Chain(
+		handler.CheckReferenceSolution(db, runner),
+		middleware.AuthenticateMiddleware,  // Verifies JWT token
+		middleware.AdminMiddleware(db),     // Requires the admin role
+		middleware.DBLoggingMiddleware(db), // Logs the request
+	))
+
+	// GET method for polling the progress of a rejudge
+	r.Handle("GET /rejudges/{id}", Chain(
+		handler.GetRejudge(db),
+		middleware.AuthenticateMiddleware, // Verifies JWT token
+		middleware.AdminMiddleware(db),    // Requires the admin role
+	))
+
 	r.Handle("GET /allsolutions", Chain(
 		handler.GetAllUserSolutions(db),
 		middleware.AuthenticateMiddleware, // Verifies JWT token
@@ -0,0 +1,100 @@
+package integration
+
+// validProblem is a problem accepted by POST /problems
+const validProblem = `{
+	"title": "Multiply Two Numbers",
+	"description": "Return a * b.",
+	"difficulty": "easy",
+	"function_name": "multiplyTwo",
+	"arguments": [{"name": "a", "type": "int"}, {"name": "b", "type": "int"}],
+	"test_cases": [
+		{"args": [2, 3], "expected": 6, "sample": true},
+		{"args": [-4, 5], "expected": -20}
+	]
+}`
+
+var Authoring = []TestCase{
+	{
+		Name:           "Create a problem as admin",
+		Method:         "POST",
+		URL:            "/problems",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body:           validProblem,
+		ExpectedStatus: 201,
+		ExpectedBody:   `"function_name":"multiplyTwo"`,
+	},
+	{
+		Name:           "Create a problem without the admin role",
+		Method:         "POST",
+		URL:            "/problems",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           validProblem,
+		ExpectedStatus: 403,
+		ExpectedBody:   "Admin access required",
+	},
+	{
+		Name:    "Create a problem with an invalid function name",
+		Method:  "POST",
+		URL:     "/problems",
+		Headers: map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body: `{"title": "Bad", "difficulty": "easy", "function_name": "multiply two",
+			"arguments": [{"name": "a", "type": "int"}], "test_cases": [{"args": [1], "expected": 1}]}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "not a valid identifier",
+	},
+	{
+		Name:    "Create a problem with an unknown difficulty",
+		Method:  "POST",
+		URL:     "/problems",
+		Headers: map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body: `{"title": "Bad", "difficulty": "trivial", "function_name": "identity",
+			"arguments": [{"name": "a", "type": "int"}], "test_cases": [{"args": [1], "expected": 1}]}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "difficulty must be",
+	},
+	{
+		Name:    "Create a problem whose test cases do not match its arguments",
+		Method:  "POST",
+		URL:     "/problems",
+		Headers: map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body: `{"title": "Bad", "difficulty": "easy", "function_name": "identity",
+			"arguments": [{"name": "a", "type": "int"}], "test_cases": [{"args": [1, 2], "expected": 1}]}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "expected 1 arguments, got 2",
+	},
+	{
+		Name:           "Patch an unknown problem",
+		Method:         "PATCH",
+		URL:            "/problems/000000000000000000000000",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body:           `{"difficulty": "hard"}`,
+		ExpectedStatus: 404,
+		ExpectedBody:   "Problem not found",
+	},
+	{
+		Name:           "Patch a problem with an unknown difficulty",
+		Method:         "PATCH",
+		URL:            "/problems/6840ec83e844d5fee940c052",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body:           `{"difficulty": "trivial"}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "difficulty must be",
+	},
+	{
+		Name:           "Replace a problem without the admin role",
+		Method:         "PUT",
+		URL:            "/problems/6840ec83e844d5fee940c052",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           validProblem,
+		ExpectedStatus: 403,
+		ExpectedBody:   "Admin access required",
+	},
+	{
+		Name:           "Delete an unknown problem",
+		Method:         "DELETE",
+		URL:            "/problems/000000000000000000000000",
+		Headers:        map[string]string{"Authorization": adminToken},
+		ExpectedStatus: 404,
+		ExpectedBody:   "Problem not found",
+	},
+}
@@ -20,3 +20,103 @@ var Compile = []TestCase{
 		ExpectedBody:   "Invalid token",
 	},
 }
+
+var CompileStream = []TestCase{
+	{
+		Name:           "Stream compile with valid token",
+		Method:         "POST",
+		URL:            "/compile/stream",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052"}`,
+		ExpectedStatus: 200,
+		ExpectedBody:   "event: summary",
+	},
+	{
+		Name:           "Stream compile with unknown problem",
+		Method:         "POST",
+		URL:            "/compile/stream",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int main(void) { return 0; }", "problemId": "000000000000000000000000"}`,
+		ExpectedStatus: 404,
+		ExpectedBody:   "Problem not found",
+	},
+	{
+		Name:           "Stream compile with invalid token",
+		Method:         "POST",
+		URL:            "/compile/stream",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": badToken},
+		Body:           `{"code": "int main(void) { return 0; }", "problemId": "6840ec83e844d5fee940c052"}`,
+		ExpectedStatus: 401,
+		ExpectedBody:   "Invalid token",
+	},
+}
+
+var CompileRun = []TestCase{
+	{
+		Name:           "Run sample test cases with valid token",
+		Method:         "POST",
+		URL:            "/compile",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "mode": "run"}`,
+		ExpectedStatus: 200,
+		ExpectedBody:   `"passed":`,
+	},
+	{
+		Name:           "Run with unknown mode",
+		Method:         "POST",
+		URL:            "/compile",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "mode": "debug"}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "Invalid mode",
+	},
+}
+
+var CompileLanguage = []TestCase{
+	{
+		Name:           "Compile in the default language",
+		Method:         "POST",
+		URL:            "/compile",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "language": "C"}`,
+		ExpectedStatus: 200,
+		ExpectedBody:   `"passed":`,
+	},
+	{
+		Name:           "Compile in a language without a judge",
+		Method:         "POST",
+		URL:            "/compile",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "def addTwo(a, b): return a + b", "problemId": "6840ec83e844d5fee940c052", "language": "cobol"}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "Unsupported language",
+	},
+	{
+		Name:           "Compile in a language the problem does not allow",
+		Method:         "POST",
+		URL:            "/compile",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "def addTwo(a, b): return a + b", "problemId": "6840ec83e844d5fee940c052", "language": "python"}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "Language not allowed for this problem",
+	},
+}
+
+var CacheStats = []TestCase{
+	{
+		Name:           "Read cache stats without the admin role",
+		Method:         "GET",
+		URL:            "/cache/stats",
+		Headers:        map[string]string{"Authorization": tokenString},
+		ExpectedStatus: 403,
+		ExpectedBody:   "Admin access required",
+	},
+	{
+		Name:           "Read cache stats as an admin",
+		Method:         "GET",
+		URL:            "/cache/stats",
+		Headers:        map[string]string{"Authorization": adminToken},
+		ExpectedStatus: 200,
+		ExpectedBody:   `"hits"`,
+	},
+}
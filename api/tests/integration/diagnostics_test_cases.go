@@ -0,0 +1,40 @@
+package integration
+
+var Diagnostics = []TestCase{
+	{
+		Name:           "Diagnostics for valid code",
+		Method:         "POST",
+		URL:            "/diagnostics",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }"}`,
+		ExpectedStatus: 200,
+		ExpectedBody:   `{"status":"Success","diagnostics":[]}`,
+	},
+	{
+		Name:           "Diagnostics for code with errors",
+		Method:         "POST",
+		URL:            "/diagnostics",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b }"}`,
+		ExpectedStatus: 200,
+		ExpectedBody:   `"severity":"error","line":1,"column":41`,
+	},
+	{
+		Name:           "Diagnostics without code",
+		Method:         "POST",
+		URL:            "/diagnostics",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "  "}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "Code is required",
+	},
+	{
+		Name:           "Diagnostics with invalid token",
+		Method:         "POST",
+		URL:            "/diagnostics",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": badToken},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }"}`,
+		ExpectedStatus: 401,
+		ExpectedBody:   "Invalid token",
+	},
+}
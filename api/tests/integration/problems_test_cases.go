@@ -7,7 +7,47 @@ var GetProblems = []TestCase{
 		URL:            "/problems",
 		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
 		ExpectedStatus: 200,
-		ExpectedBody:   `[{"id":`,
+		ExpectedBody:   `{"problems":[{"id":`,
+	},
+	{
+		Name:           "Get problem summaries with the user's status",
+		Method:         "GET",
+		URL:            "/problems?sort=title",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		ExpectedStatus: 200,
+		ExpectedBody:   `"status":"`,
+	},
+	{
+		Name:           "Get easy problems sorted by title",
+		Method:         "GET",
+		URL:            "/problems?difficulty=easy&sort=title&limit=2",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		ExpectedStatus: 200,
+		ExpectedBody:   `"total":`,
+	},
+	{
+		Name:           "Search problems",
+		Method:         "GET",
+		URL:            "/problems?q=add",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		ExpectedStatus: 200,
+		ExpectedBody:   `"acceptance_rate":`,
+	},
+	{
+		Name:           "Get problems with an unknown sort",
+		Method:         "GET",
+		URL:            "/problems?sort=popularity",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		ExpectedStatus: 400,
+		ExpectedBody:   "unknown sort",
+	},
+	{
+		Name:           "Get problems with a malformed cursor",
+		Method:         "GET",
+		URL:            "/problems?cursor=not-a-cursor",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		ExpectedStatus: 400,
+		ExpectedBody:   "malformed cursor",
 	},
 	{
 		Name:           "Get all problems with invalid token",
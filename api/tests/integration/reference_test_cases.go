@@ -0,0 +1,47 @@
+package integration
+
+var Reference = []TestCase{
+	{
+		Name:           "Set a reference solution without the admin role",
+		Method:         "PUT",
+		URL:            "/problems/6840ec83e844d5fee940c052/reference",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"solution": "int addTwo(int a, int b) { return a + b; }", "language": "c"}`,
+		ExpectedStatus: 403,
+		ExpectedBody:   "Admin access required",
+	},
+	{
+		Name:           "Set an empty reference solution",
+		Method:         "PUT",
+		URL:            "/problems/6840ec83e844d5fee940c052/reference",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body:           `{"solution": "", "language": "c"}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "Reference solution is required",
+	},
+	{
+		Name:           "Set the reference solution of an unknown problem",
+		Method:         "PUT",
+		URL:            "/problems/000000000000000000000000/reference",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body:           `{"solution": "int addTwo(int a, int b) { return a + b; }", "language": "c"}`,
+		ExpectedStatus: 404,
+		ExpectedBody:   "Problem not found",
+	},
+	{
+		Name:           "Check the reference solution of an unknown problem",
+		Method:         "POST",
+		URL:            "/problems/000000000000000000000000/reference/check",
+		Headers:        map[string]string{"Authorization": adminToken},
+		ExpectedStatus: 404,
+		ExpectedBody:   "Problem not found",
+	},
+	{
+		Name:           "Check the reference solution without the admin role",
+		Method:         "POST",
+		URL:            "/problems/6840ec83e844d5fee940c052/reference/check",
+		Headers:        map[string]string{"Authorization": tokenString},
+		ExpectedStatus: 403,
+		ExpectedBody:   "Admin access required",
+	},
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"fmt"
+	"learning_go/internal/auth"
+)
+
+// adminUsername is given the admin role by TestMain
+const adminUsername = "testadmin"
+
+func GetAdminToken() string {
+	jwt, err := auth.CreateToken(adminUsername)
+
+	if err != nil {
+		panic(fmt.Sprintf("Error creating token: %v", err))
+	}
+
+	return fmt.Sprintf("Bearer %s", jwt)
+}
+
+var adminToken = GetAdminToken()
+
+var Rejudge = []TestCase{
+	{
+		Name:           "Rejudge a problem as admin",
+		Method:         "POST",
+		URL:            "/problems/6840ec83e844d5fee940c052/rejudge",
+		Headers:        map[string]string{"Authorization": adminToken},
+		ExpectedStatus: 202,
+		ExpectedBody:   `"state":"running"`,
+	},
+	{
+		Name:           "Rejudge an unknown problem",
+		Method:         "POST",
+		URL:            "/problems/000000000000000000000000/rejudge",
+		Headers:        map[string]string{"Authorization": adminToken},
+		ExpectedStatus: 404,
+		ExpectedBody:   "Problem not found",
+	},
+	{
+		Name:           "Rejudge without the admin role",
+		Method:         "POST",
+		URL:            "/problems/6840ec83e844d5fee940c052/rejudge",
+		Headers:        map[string]string{"Authorization": tokenString},
+		ExpectedStatus: 403,
+		ExpectedBody:   "Admin access required",
+	},
+	{
+		Name:           "Get an unknown rejudge",
+		Method:         "GET",
+		URL:            "/rejudges/not-an-id",
+		Headers:        map[string]string{"Authorization": adminToken},
+		ExpectedStatus: 404,
+		ExpectedBody:   "Rejudge not found",
+	},
+}
@@ -0,0 +1,40 @@
+package integration
+
+var RunCode = []TestCase{
+	{
+		Name:           "Run code on custom inputs with valid token",
+		Method:         "POST",
+		URL:            "/run",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "inputs": [[1, 2], [-3, 4]]}`,
+		ExpectedStatus: 200,
+		ExpectedBody:   `{"results":[{"input":[1,2]`,
+	},
+	{
+		Name:           "Run code with wrong number of arguments",
+		Method:         "POST",
+		URL:            "/run",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "inputs": [[1]]}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "Invalid input 0",
+	},
+	{
+		Name:           "Run code without inputs",
+		Method:         "POST",
+		URL:            "/run",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "inputs": []}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "At least one input is required",
+	},
+	{
+		Name:           "Run code with invalid token",
+		Method:         "POST",
+		URL:            "/run",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": badToken},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "inputs": [[1, 2]]}`,
+		ExpectedStatus: 401,
+		ExpectedBody:   "Invalid token",
+	},
+}
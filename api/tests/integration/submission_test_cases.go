@@ -0,0 +1,47 @@
+package integration
+
+var Submissions = []TestCase{
+	{
+		Name:           "Queue submission with valid token",
+		Method:         "POST",
+		URL:            "/submissions",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052"}`,
+		ExpectedStatus: 202,
+		ExpectedBody:   `"state":"queued"`,
+	},
+	{
+		Name:           "Queue submission with empty code",
+		Method:         "POST",
+		URL:            "/submissions",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"code": "", "problemId": "6840ec83e844d5fee940c052"}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "Code is required",
+	},
+	{
+		Name:           "Queue submission with invalid token",
+		Method:         "POST",
+		URL:            "/submissions",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": badToken},
+		Body:           `{"code": "int main(void) { return 0; }", "problemId": "6840ec83e844d5fee940c052"}`,
+		ExpectedStatus: 401,
+		ExpectedBody:   "Invalid token",
+	},
+	{
+		Name:           "Get submission with invalid ID",
+		Method:         "GET",
+		URL:            "/submissions/not-an-id",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		ExpectedStatus: 400,
+		ExpectedBody:   "Invalid submission ID",
+	},
+	{
+		Name:           "Get unknown submission",
+		Method:         "GET",
+		URL:            "/submissions/000000000000000000000000",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		ExpectedStatus: 404,
+		ExpectedBody:   "Submission not found",
+	},
+}
@@ -0,0 +1,56 @@
+package integration
+
+var Tags = []TestCase{
+	{
+		Name:           "Get tags with valid token",
+		Method:         "GET",
+		URL:            "/tags",
+		Headers:        map[string]string{"Authorization": tokenString},
+		ExpectedStatus: 200,
+		ExpectedBody:   "[",
+	},
+	{
+		Name:           "Get tags with invalid token",
+		Method:         "GET",
+		URL:            "/tags",
+		Headers:        map[string]string{"Authorization": badToken},
+		ExpectedStatus: 401,
+		ExpectedBody:   "Invalid token",
+	},
+	{
+		Name:           "Create a tag without the admin role",
+		Method:         "POST",
+		URL:            "/tags",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": tokenString},
+		Body:           `{"name": "pointers", "week": 5}`,
+		ExpectedStatus: 403,
+		ExpectedBody:   "Admin access required",
+	},
+	{
+		Name:           "Create a tag with an invalid name",
+		Method:         "POST",
+		URL:            "/tags",
+		Headers:        map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body:           `{"name": "two words"}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "invalid tag",
+	},
+	{
+		Name:           "Delete an unknown tag",
+		Method:         "DELETE",
+		URL:            "/tags/not-a-tag",
+		Headers:        map[string]string{"Authorization": adminToken},
+		ExpectedStatus: 404,
+		ExpectedBody:   "Tag not found",
+	},
+	{
+		Name:    "Create a problem with an unknown tag",
+		Method:  "POST",
+		URL:     "/problems",
+		Headers: map[string]string{"Content-Type": "application/json", "Authorization": adminToken},
+		Body: `{"title": "Identity", "difficulty": "easy", "function_name": "identity", "tags": ["not-a-tag"],
+			"arguments": [{"name": "a", "type": "int"}], "test_cases": [{"args": [1], "expected": 1}]}`,
+		ExpectedStatus: 400,
+		ExpectedBody:   "unknown tags not-a-tag",
+	},
+}
@@ -2,7 +2,14 @@ package integration
 
 import (
 	"context"
+	"encoding/json"
+	"learning_go/internal/cache"
 	"learning_go/internal/database"
+	handler "learning_go/internal/handlers"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"learning_go/internal/queue"
+	"learning_go/internal/rejudge"
 	"learning_go/internal/router"
 	"log"
 	"net/http"
@@ -12,11 +19,68 @@ import (
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var testDB *mongo.Database
 
+var testRunner = &judge.FakeRunner{Diagnose: missingSemicolons}
+
+// testJudge routes the default language and python to the fake runner
+var testJudge = newTestJudge()
+
+func newTestJudge() *judge.Registry {
+	registry := judge.NewRegistry()
+	registry.Register(model.DefaultLanguage, testRunner)
+	registry.Register("python", testRunner)
+	return registry
+}
+
+// missingSemicolons reports every closing brace that directly follows a
+// statement without a semicolon, standing in for the compiler in tests
+func missingSemicolons(program string) []model.Diagnostic {
+	diagnostics := []model.Diagnostic{}
+	for lineIndex, line := range strings.Split(program, "\n") {
+		for i, ch := range line {
+			if ch != '}' {
+				continue
+			}
+			previous := strings.TrimRight(line[:i], " \t")
+			if previous != "" && !strings.ContainsAny(previous[len(previous)-1:], ";{}") {
+				diagnostics = append(diagnostics, model.Diagnostic{
+					Severity: model.SeverityError,
+					Line:     lineIndex + 1,
+					Column:   i + 1,
+					Message:  "expected ';' before '}'",
+				})
+			}
+		}
+	}
+	return diagnostics
+}
+
+var testQueue *queue.Queue
+
+var testRejudges *rejudge.Manager
+
+var testCache = cache.NewCompileCache(100, time.Hour, nil)
+
+var testDiagnosticsCache = cache.NewCompileCache(100, time.Hour, nil)
+
+// newTestRouter builds the API router backed by the test database and the fake judge
+func newTestRouter() http.Handler {
+	return router.NewWithDB(testDB, router.Services{
+		Runner:           testJudge,
+		Submissions:      testQueue,
+		CompileCache:     testCache,
+		DiagnosticsCache: testDiagnosticsCache,
+		Rejudges:         testRejudges,
+	})
+}
+
 // discardLogger implements io.Writer and discards all writes
 type discardLogger struct{}
 
@@ -48,10 +112,37 @@ func TestMain(m *testing.M) {
 	}
 	testDB = mongoDB.Database
 
+	if err := model.NewSubmissionService(testDB).EnsureIndexes(ctx); err != nil {
+		panic(err)
+	}
+	if err := model.NewProblemService(testDB).EnsureIndexes(ctx); err != nil {
+		panic(err)
+	}
+
+	// Give the admin test user its role
+	_, err = testDB.Collection("users").UpdateOne(ctx,
+		bson.M{"username": adminUsername},
+		bson.M{"$set": bson.M{"username": adminUsername, "role": model.RoleAdmin}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	// Judge queued submissions in the background with the fake runner
+	queueCtx, stopQueue := context.WithCancel(context.Background())
+	testQueue = queue.New(testDB, testJudge, 1, 10)
+	testQueue.Start(queueCtx)
+	testRejudges = rejudge.New(testDB, testJudge, testCache)
+	testRejudges.Start(queueCtx)
+
 	// Run tests
 	code := m.Run()
 
 	// Clean up
+	stopQueue()
+	testQueue.Wait()
+	testRejudges.Wait()
 	if err := mongoDB.Disconnect(ctx); err != nil {
 		panic(err)
 	}
@@ -59,13 +150,14 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-func TestLogInRoute(t *testing.T) {
+// runRouteTests sends each test case's request through the router and checks
+// the response status and, when set, that the body contains ExpectedBody
+func runRouteTests(t *testing.T, cases []TestCase) {
 	// Create test logger
 	logger := &testLogger{t}
-	handler := router.NewWithDB(testDB)
+	handler := newTestRouter()
 
-	// Step 2: iterate over each TestCase in unit.logIn
-	for _, tc := range LogIn {
+	for _, tc := range cases {
 		t.Run(tc.Name, func(t *testing.T) {
 			logger.Printf("Running test: %s", tc.Name)
 			// Build the HTTP request
@@ -107,162 +199,302 @@ func TestLogInRoute(t *testing.T) {
 	}
 }
 
+func TestLogInRoute(t *testing.T) {
+	runRouteTests(t, LogIn)
+}
+
 func TestSignUpRoute(t *testing.T) {
-	// Create test logger
-	logger := &testLogger{t}
-	handler := router.NewWithDB(testDB)
+	runRouteTests(t, SignUp)
+}
 
-	for _, tc := range SignUp {
-		t.Run(tc.Name, func(t *testing.T) {
-			logger.Printf("Running test: %s", tc.Name)
-			var req *http.Request
-			if tc.Body != "" {
-				req = httptest.NewRequest(tc.Method, tc.URL, strings.NewReader(tc.Body))
-			} else {
-				req = httptest.NewRequest(tc.Method, tc.URL, nil)
-			}
-			for k, v := range tc.Headers {
-				req.Header.Set(k, v)
-			}
+func TestLogsRoute(t *testing.T) {
+	runRouteTests(t, GetLogs)
+}
 
-			rr := httptest.NewRecorder()
-			handler.ServeHTTP(rr, req)
+func TestGetProblems(t *testing.T) {
+	runRouteTests(t, GetProblems)
+}
 
-			if rr.Code != tc.ExpectedStatus {
-				t.Errorf(
-					"Test %q: expected status %d, got %d. Body=%q",
-					tc.Name, tc.ExpectedStatus, rr.Code, rr.Body.String(),
-				)
-			}
-			if tc.ExpectedBody != "" {
-				body := rr.Body.String()
-				if !strings.Contains(body, tc.ExpectedBody) {
-					t.Errorf(
-						"Test %q: expected body to contain %q, but got %q",
-						tc.Name, tc.ExpectedBody, body,
-					)
-				}
-			}
-		})
+func TestGetProblemByID(t *testing.T) {
+	runRouteTests(t, GetProblemByID)
+}
+
+func TestSubmissionsRoute(t *testing.T) {
+	runRouteTests(t, Submissions)
+}
+
+func TestCompileStreamRoute(t *testing.T) {
+	runRouteTests(t, CompileStream)
+}
+
+func TestCompileRunRoute(t *testing.T) {
+	runRouteTests(t, CompileRun)
+}
+
+func TestRunRoute(t *testing.T) {
+	runRouteTests(t, RunCode)
+}
+
+func TestDiagnosticsRoute(t *testing.T) {
+	runRouteTests(t, Diagnostics)
+}
+
+func TestCompileLanguageRoute(t *testing.T) {
+	runRouteTests(t, CompileLanguage)
+}
+
+func TestCacheStatsRoute(t *testing.T) {
+	runRouteTests(t, CacheStats)
+}
+
+func TestRejudgeRoute(t *testing.T) {
+	runRouteTests(t, Rejudge)
+}
+
+func TestReferenceRoute(t *testing.T) {
+	runRouteTests(t, Reference)
+}
+
+func TestAuthoringRoute(t *testing.T) {
+	runRouteTests(t, Authoring)
+}
+
+func TestTagsRoute(t *testing.T) {
+	runRouteTests(t, Tags)
+}
+
+func TestPatchProblemTestCases(t *testing.T) {
+	handler := newTestRouter()
+	send := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", adminToken)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	created := send("POST", "/problems", validProblem)
+	if created.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d. Body=%q", created.Code, created.Body.String())
+	}
+	location := created.Header().Get("Location")
+	id := strings.TrimPrefix(location, "/problems/")
+	t.Cleanup(func() { send("DELETE", location, "") })
+
+	// The first test case was a sample with an expected output of 6
+	patched := send("PATCH", location, `{"test_cases": [{"input": "3 3", "output": "9"}]}`)
+	if patched.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body=%q", patched.Code, patched.Body.String())
+	}
+
+	stored, err := model.NewProblemService(testDB).GetProblemByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to load the patched problem: %v", err)
+	}
+	if stored.Title != "Multiply Two Numbers" {
+		t.Errorf("expected the title to be kept, got %q", stored.Title)
+	}
+	if len(stored.TestCases) != 1 {
+		t.Fatalf("expected 1 test case, got %d", len(stored.TestCases))
+	}
+	expected, err := stored.ExpectedOutput(0)
+	if err != nil || expected != 9 {
+		t.Errorf("expected the patched output 9, got %v (%v)", expected, err)
+	}
+	if stored.TestCases[0].Sample {
+		t.Error("expected the patched test case to be hidden")
 	}
 }
 
-func TestLogsRoute(t *testing.T) {
-	// Create test logger
-	logger := &testLogger{t}
-	handler := router.NewWithDB(testDB)
+func TestDeleteTagKeepsProblemRevision(t *testing.T) {
+	ctx := context.Background()
+	problems := model.NewProblemService(testDB)
+	tags := model.NewTagService(testDB)
 
-	for _, tc := range GetLogs {
-		t.Run(tc.Name, func(t *testing.T) {
-			logger.Printf("Running test: %s", tc.Name)
-			var req *http.Request
-			if tc.Body != "" {
-				req = httptest.NewRequest(tc.Method, tc.URL, strings.NewReader(tc.Body))
-			} else {
-				req = httptest.NewRequest(tc.Method, tc.URL, nil)
-			}
-			for k, v := range tc.Headers {
-				req.Header.Set(k, v)
-			}
+	if err := tags.CreateTag(ctx, &model.Tag{Name: "quokka-tag"}); err != nil {
+		t.Fatalf("failed to create the tag: %v", err)
+	}
+	revision := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	inserted, err := problems.Collection.InsertOne(ctx, bson.M{
+		"title":      "Tagged Quokka",
+		"slug":       "tagged-quokka",
+		"tags":       bson.A{"quokka-tag"},
+		"updated_at": revision,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert the problem: %v", err)
+	}
+	t.Cleanup(func() { problems.Collection.DeleteOne(ctx, bson.M{"_id": inserted.InsertedID}) })
 
-			rr := httptest.NewRecorder()
-			handler.ServeHTTP(rr, req)
+	if err := tags.DeleteTag(ctx, "quokka-tag"); err != nil {
+		t.Fatalf("failed to delete the tag: %v", err)
+	}
+	stored, err := problems.GetProblemByID(ctx, inserted.InsertedID.(primitive.ObjectID).Hex())
+	if err != nil {
+		t.Fatalf("failed to load the problem: %v", err)
+	}
+	if len(stored.Tags) != 0 {
+		t.Errorf("expected the tag to be unassigned, got %v", stored.Tags)
+	}
+	if !stored.UpdatedAt.Equal(revision) {
+		t.Errorf("expected the revision to stay %s, got %s", revision, stored.UpdatedAt)
+	}
+}
 
-			if rr.Code != tc.ExpectedStatus {
-				t.Errorf(
-					"Test %q: expected status %d, got %d. Body=%q",
-					tc.Name, tc.ExpectedStatus, rr.Code, rr.Body.String(),
-				)
-			}
-			if tc.ExpectedBody != "" {
-				body := rr.Body.String()
-				if !strings.Contains(body, tc.ExpectedBody) {
-					t.Errorf(
-						"Test %q: expected body to contain %q, but got %q",
-						tc.Name, tc.ExpectedBody, body,
-					)
-				}
-			}
-		})
+func TestRunRejectsIncompleteJudgeResponse(t *testing.T) {
+	dropLast := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		return &judge.Response{Results: make([]judge.Result, len(req.TestCases)-1)}, nil
+	})
+
+	req := httptest.NewRequest("POST", "/run", strings.NewReader(
+		`{"code": "int addTwo(int a, int b) { return a + b; }", "problemId": "6840ec83e844d5fee940c052", "inputs": [[1, 2], [-3, 4]]}`))
+	rr := httptest.NewRecorder()
+	handler.RunCode(testDB, dropLast).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadGateway || !strings.Contains(rr.Body.String(), "incomplete response") {
+		t.Errorf("expected a 502, got %d: %s", rr.Code, rr.Body.String())
 	}
 }
 
-func TestGetProblems(t *testing.T) {
-	// Create test logger
-	logger := &testLogger{t}
-	handler := router.NewWithDB(testDB)
+func TestMarkLegacySamples(t *testing.T) {
+	ctx := context.Background()
+	problems := model.NewProblemService(testDB)
+	migrations := testDB.Collection("migrations")
+	if _, err := migrations.DeleteOne(ctx, bson.M{"_id": "legacy_sample_test_cases"}); err != nil {
+		t.Fatalf("failed to reset the migration: %v", err)
+	}
 
-	for _, tc := range GetProblems {
-		t.Run(tc.Name, func(t *testing.T) {
-			logger.Printf("Running test: %s", tc.Name)
-			var req *http.Request
-			if tc.Body != "" {
-				req = httptest.NewRequest(tc.Method, tc.URL, strings.NewReader(tc.Body))
-			} else {
-				req = httptest.NewRequest(tc.Method, tc.URL, nil)
-			}
-			for k, v := range tc.Headers {
-				req.Header.Set(k, v)
-			}
+	insert := func(title string, testCases bson.A) string {
+		inserted, err := problems.Collection.InsertOne(ctx, bson.M{"title": title, "test_cases": testCases})
+		if err != nil {
+			t.Fatalf("failed to insert %q: %v", title, err)
+		}
+		t.Cleanup(func() { problems.Collection.DeleteOne(ctx, bson.M{"_id": inserted.InsertedID}) })
+		return inserted.InsertedID.(primitive.ObjectID).Hex()
+	}
+	legacy := insert("Legacy Quokka", bson.A{bson.M{"args": bson.A{1}}, bson.M{"args": bson.A{2}}})
+	authored := insert("Authored Quokka", bson.A{bson.M{"args": bson.A{1}}, bson.M{"args": bson.A{2}, "sample": true}})
 
-			rr := httptest.NewRecorder()
-			handler.ServeHTTP(rr, req)
+	if _, err := model.MarkLegacySamples(ctx, testDB); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	later := insert("Later Quokka", bson.A{bson.M{"args": bson.A{1}}})
+	if _, err := model.MarkLegacySamples(ctx, testDB); err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
 
-			if rr.Code != tc.ExpectedStatus {
-				t.Errorf(
-					"Test %q: expected status %d, got %d. Body=%q",
-					tc.Name, tc.ExpectedStatus, rr.Code, rr.Body.String(),
-				)
-			}
-			if tc.ExpectedBody != "" {
-				body := rr.Body.String()
-				if !strings.Contains(body, tc.ExpectedBody) {
-					t.Errorf(
-						"Test %q: expected body to contain %q, but got %q",
-						tc.Name, tc.ExpectedBody, body,
-					)
-				}
+	expected := map[string][]bool{legacy: {true, false}, authored: {false, true}, later: {false}}
+	for id, samples := range expected {
+		problem, err := problems.GetProblemByID(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", id, err)
+		}
+		for i, sample := range samples {
+			if problem.TestCases[i].Sample != sample {
+				t.Errorf("%s: expected test case %d sample=%v", problem.Title, i, sample)
 			}
-		})
+		}
 	}
 }
 
-func TestGetProblemByID(t *testing.T) {
-	// Create test logger
-	logger := &testLogger{t}
-	handler := router.NewWithDB(testDB)
+func TestBackfillProblemSlugs(t *testing.T) {
+	ctx := context.Background()
+	problems := model.NewProblemService(testDB)
+
+	// A problem seeded before slugs existed
+	legacy := bson.M{
+		"title":         "Legacy Slugless Problem",
+		"difficulty":    "easy",
+		"function_name": "identity",
+		"arguments":     bson.A{bson.M{"name": "a", "type": "int"}},
+		"test_cases":    bson.A{bson.M{"args": bson.A{1}, "expected": 1}},
+	}
+	inserted, err := problems.Collection.InsertOne(ctx, legacy)
+	if err != nil {
+		t.Fatalf("failed to insert the legacy problem: %v", err)
+	}
+	t.Cleanup(func() { problems.Collection.DeleteOne(ctx, bson.M{"_id": inserted.InsertedID}) })
 
-	for _, tc := range GetProblemByID {
-		t.Run(tc.Name, func(t *testing.T) {
-			logger.Printf("Running test: %s", tc.Name)
-			var req *http.Request
-			if tc.Body != "" {
-				req = httptest.NewRequest(tc.Method, tc.URL, strings.NewReader(tc.Body))
-			} else {
-				req = httptest.NewRequest(tc.Method, tc.URL, nil)
-			}
-			for k, v := range tc.Headers {
-				req.Header.Set(k, v)
-			}
+	if _, err := model.BackfillProblemSlugs(ctx, testDB); err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	stored, err := problems.GetProblemBySlug(ctx, "legacy-slugless-problem")
+	if err != nil {
+		t.Fatalf("expected the problem to be found by its backfilled slug: %v", err)
+	}
 
-			rr := httptest.NewRecorder()
-			handler.ServeHTTP(rr, req)
+	// Importing its exported package updates it instead of creating a copy
+	stored.Description = "Return a."
+	created, err := problems.UpsertProblemBySlug(ctx, stored)
+	if err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if created || stored.ID != inserted.InsertedID {
+		t.Errorf("expected the legacy problem to be updated, got a new problem %s", stored.ID.Hex())
+	}
+}
 
-			if rr.Code != tc.ExpectedStatus {
-				t.Errorf(
-					"Test %q: expected status %d, got %d. Body=%q",
-					tc.Name, tc.ExpectedStatus, rr.Code, rr.Body.String(),
-				)
-			}
-			if tc.ExpectedBody != "" {
-				body := rr.Body.String()
-				if !strings.Contains(body, tc.ExpectedBody) {
-					t.Errorf(
-						"Test %q: expected body to contain %q, but got %q",
-						tc.Name, tc.ExpectedBody, body,
-					)
-				}
-			}
-		})
+func TestProblemStatuses(t *testing.T) {
+	ctx := context.Background()
+	problems := model.NewProblemService(testDB)
+	submissions := model.NewSubmissionService(testDB)
+
+	// Problems only this test lists, by searching for their common title word
+	ids := map[string]primitive.ObjectID{}
+	for _, title := range []string{"Quokka Solved", "Quokka Attempted", "Quokka Untouched"} {
+		problem := &model.Problem{
+			Title:        title,
+			Difficulty:   model.DifficultyEasy,
+			FunctionName: "identity",
+			Arguments:    []model.ParamType{{Name: "a", Type: model.TypeInt}},
+			TestCases:    []model.TestCase{{Args: []interface{}{1}, Expected: 1}},
+		}
+		if err := problems.CreateProblem(ctx, problem); err != nil {
+			t.Fatalf("failed to create %q: %v", title, err)
+		}
+		ids[title] = problem.ID
+	}
+	seeded := []interface{}{
+		model.Submission{Username: "testuser", ProblemID: ids["Quokka Solved"], State: model.SubmissionDone, Verdict: model.VerdictWrongAnswer},
+		model.Submission{Username: "testuser", ProblemID: ids["Quokka Solved"], State: model.SubmissionDone, Verdict: model.VerdictAccepted},
+		model.Submission{Username: "testuser", ProblemID: ids["Quokka Attempted"], State: model.SubmissionDone, Verdict: model.VerdictWrongAnswer},
+		// Other users' submissions do not count
+		model.Submission{Username: "someone-else", ProblemID: ids["Quokka Untouched"], State: model.SubmissionDone, Verdict: model.VerdictAccepted},
+	}
+	if _, err := submissions.Collection.InsertMany(ctx, seeded); err != nil {
+		t.Fatalf("failed to seed submissions: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, id := range ids {
+			problems.Collection.DeleteOne(ctx, bson.M{"_id": id})
+			submissions.Collection.DeleteMany(ctx, bson.M{"problem_id": id})
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/problems?q=quokka&limit=10", nil)
+	req.Header.Set("Authorization", tokenString)
+	rr := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body=%q", rr.Code, rr.Body.String())
+	}
+
+	var page model.ProblemPage
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode the problem list: %v", err)
+	}
+	expected := map[string]string{
+		"Quokka Solved":    model.ProblemSolved,
+		"Quokka Attempted": model.ProblemAttempted,
+		"Quokka Untouched": model.ProblemNotAttempted,
+	}
+	if len(page.Problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d", len(expected), len(page.Problems))
+	}
+	for _, item := range page.Problems {
+		if item.Status != expected[item.Title] {
+			t.Errorf("%s: expected status %q, got %q", item.Title, expected[item.Title], item.Status)
+		}
 	}
 }
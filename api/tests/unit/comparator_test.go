@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestComparators(t *testing.T) {
+	model.RegisterChecker("test-same-length", func(expected, actual interface{}) bool {
+		a, okA := expected.(string)
+		b, okB := actual.(string)
+		return okA && okB && len(a) == len(b)
+	})
+
+	list := func(values ...interface{}) []interface{} { return values }
+
+	tests := []struct {
+		name       string
+		comparator model.Comparator
+		expected   interface{}
+		actual     interface{}
+		want       bool
+	}{
+		{"exact int equal", model.Comparator{Type: model.CompareExact}, 3, 3, true},
+		{"exact int differs", model.Comparator{}, 3, 4, false},
+		{"exact list order matters", model.Comparator{}, list(1, 2), list(2, 1), false},
+		{"int equal", model.Comparator{Type: model.CompareInt}, 42, 42, true},
+		{"int rejects string", model.Comparator{Type: model.CompareInt}, 0, "abc", false},
+		{"string equal", model.Comparator{Type: model.CompareString}, "abc", "abc", true},
+		{"string is case sensitive", model.Comparator{Type: model.CompareString}, "abc", "ABC", false},
+		{"text ignores case", model.Comparator{Type: model.CompareText}, "Hello World", "hello world", true},
+		{"text ignores whitespace", model.Comparator{Type: model.CompareText}, "a b  c", " a\tb\nc ", true},
+		{"text still compares words", model.Comparator{Type: model.CompareText}, "ab c", "a bc", false},
+		{"float within abs", model.Comparator{Type: model.CompareFloat, AbsTolerance: 1e-6}, 0.1 + 0.2, 0.3, true},
+		{"float outside abs", model.Comparator{Type: model.CompareFloat, AbsTolerance: 1e-6}, 1.0, 1.001, false},
+		{"float within rel", model.Comparator{Type: model.CompareFloat, RelTolerance: 1e-3}, 1000.0, 1000.5, true},
+		{"float outside rel", model.Comparator{Type: model.CompareFloat, RelTolerance: 1e-3}, 1000.0, 1002.0, false},
+		{"float list", model.Comparator{Type: model.CompareFloat, AbsTolerance: 0.01}, list(1.0, 2.0), list(1.005, 1.999), true},
+		{"float list length", model.Comparator{Type: model.CompareFloat, AbsTolerance: 0.01}, list(1.0, 2.0), list(1.0), false},
+		{"unordered equal", model.Comparator{Type: model.CompareUnordered}, list(1, 2, 2, 3), list(2, 3, 1, 2), true},
+		{"unordered multiplicity", model.Comparator{Type: model.CompareUnordered}, list(1, 2, 2), list(1, 1, 2), false},
+		{"custom checker", model.Comparator{Type: model.CompareCustom, Checker: "test-same-length"}, "abc", "xyz", true},
+		{"custom checker fails", model.Comparator{Type: model.CompareCustom, Checker: "test-same-length"}, "abc", "xy", false},
+		{"unknown checker", model.Comparator{Type: model.CompareCustom, Checker: "missing"}, "abc", "abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.comparator.Compare(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("Compare(%v, %v) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparatorValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparator model.Comparator
+		returnType string
+		wantErr    string
+	}{
+		{"exact any type", model.Comparator{Type: model.CompareExact}, model.TypeStringArray, ""},
+		{"float on float", model.Comparator{Type: model.CompareFloat, AbsTolerance: 1e-9}, model.TypeFloat, ""},
+		{"float on int", model.Comparator{Type: model.CompareFloat}, model.TypeInt, "requires return type"},
+		{"negative tolerance", model.Comparator{Type: model.CompareFloat, RelTolerance: -1}, model.TypeFloat, "must not be negative"},
+		{"text on int", model.Comparator{Type: model.CompareText}, model.TypeInt, "requires return type"},
+		{"unordered on scalar", model.Comparator{Type: model.CompareUnordered}, model.TypeInt, "requires an array"},
+		{"unknown checker", model.Comparator{Type: model.CompareCustom, Checker: "missing"}, model.TypeInt, "unknown checker"},
+		{"unknown type", model.Comparator{Type: "fuzzy"}, model.TypeInt, "unknown comparator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.comparator.Validate(tt.returnType)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGraderUsesProblemComparator(t *testing.T) {
+	problem := &model.Problem{
+		FunctionName: "average",
+		Arguments:    []model.ParamType{{Name: "a", Type: model.TypeInt}, {Name: "b", Type: model.TypeInt}},
+		ReturnType:   model.TypeFloat,
+		Comparator:   &model.Comparator{Type: model.CompareFloat, AbsTolerance: 1e-6},
+		TestCases: []model.TestCase{
+			{Args: []interface{}{1, 2}, Expected: 1.5, Sample: true},
+			{Args: []interface{}{1, 1}, Expected: 1.0},
+		},
+	}
+	if err := problem.NormalizeTestCases(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	response := judge.BuildResponse(problem, &judge.Response{Results: []judge.Result{
+		{Output: 1.5000000001},
+		{Output: 1.1},
+	}})
+
+	if response.Passed != 1 || response.Total != 2 {
+		t.Errorf("expected 1/2 passed, got %d/%d", response.Passed, response.Total)
+	}
+	if response.Verdict != model.VerdictWrongAnswer {
+		t.Errorf("expected WA, got %s", response.Verdict)
+	}
+}
@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/cache"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+var addTwo = &model.Problem{
+	FunctionName: "addTwo",
+	Arguments:    []model.ParamType{{Name: "a", Type: "int"}, {Name: "b", Type: "int"}},
+	TestCases: []model.TestCase{
+		{Args: []interface{}{5, 3}, Expected: 8, Sample: true},
+		{Args: []interface{}{10, 20}, Expected: 30},
+	},
+}
+
+func TestCompileCacheCoalescesConcurrentRequests(t *testing.T) {
+	runner := &judge.FakeRunner{Delay: 100 * time.Millisecond}
+	compileCache := cache.NewCompileCache(10, time.Hour, nil)
+
+	const clients = 20
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	entries := make([]*cache.CompileCacheEntry, clients)
+	errs := make([]error, clients)
+
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			entries[i], errs[i] = compileCache.Do(context.Background(), "problem:rev:code", func() (*model.CompileResponse, error) {
+				return judge.Evaluate(context.Background(), runner, addTwo, "int addTwo(int a, int b) { return 0; }", model.DefaultLanguage)
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if calls := runner.Calls(); calls != 1 {
+		t.Fatalf("expected the judge to be called once, got %d calls", calls)
+	}
+	for i := 0; i < clients; i++ {
+		if errs[i] != nil {
+			t.Fatalf("client %d: unexpected error %v", i, errs[i])
+		}
+		if entries[i] != entries[0] {
+			t.Errorf("client %d: expected the shared response", i)
+		}
+	}
+
+	stats := compileCache.Stats()
+	if stats.Coalesced+stats.Hits != clients-1 {
+		t.Errorf("expected %d coalesced or cached requests, got %+v", clients-1, stats)
+	}
+}
+
+func TestCompileCacheDoesNotCoalesceDifferentKeys(t *testing.T) {
+	runner := &judge.FakeRunner{Delay: 50 * time.Millisecond}
+	compileCache := cache.NewCompileCache(10, time.Hour, nil)
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"problem:rev:a", "problem:rev:b", "problem:rev:c"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			compileCache.Do(context.Background(), key, func() (*model.CompileResponse, error) {
+				return judge.Evaluate(context.Background(), runner, addTwo, key, model.DefaultLanguage)
+			})
+		}(key)
+	}
+	wg.Wait()
+
+	if calls := runner.Calls(); calls != 3 {
+		t.Fatalf("expected one judge call per key, got %d calls", calls)
+	}
+}
+
+func TestCompileCacheDoesNotCacheErrors(t *testing.T) {
+	compileCache := cache.NewCompileCache(10, time.Hour, nil)
+	failure := errors.New("judge unavailable")
+
+	calls := 0
+	compute := func() (*model.CompileResponse, error) {
+		calls++
+		return nil, failure
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := compileCache.Do(context.Background(), "problem:rev:code", compute); !errors.Is(err, failure) {
+			t.Fatalf("expected judge error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected failed responses to be retried, got %d calls", calls)
+	}
+}
+
+func TestCompileCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	compileCache := cache.NewCompileCache(2, time.Hour, nil)
+
+	compileCache.Set(ctx, "a", model.CompileResponse{}, 200)
+	compileCache.Set(ctx, "b", model.CompileResponse{}, 200)
+	compileCache.Get(ctx, "a") // a becomes the most recently used entry
+	compileCache.Set(ctx, "c", model.CompileResponse{}, 200)
+
+	if _, ok := compileCache.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := compileCache.Get(ctx, "a"); !ok {
+		t.Error("expected a to stay cached")
+	}
+
+	stats := compileCache.Stats()
+	if stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
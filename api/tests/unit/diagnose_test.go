@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	compileError := judge.Result{Error: "expected ';'", Line: 1, Column: 41}
+	tests := []struct {
+		name     string
+		response judge.Response
+		errors   int
+		err      error
+	}{
+		{"clean code", judge.Response{Diagnostics: []model.Diagnostic{}}, 0, nil},
+		{"reported diagnostics", judge.Response{Diagnostics: []model.Diagnostic{
+			{Severity: model.SeverityWarning}, {Severity: model.SeverityError},
+		}}, 1, nil},
+		{"first error as a result", judge.Response{Results: []judge.Result{compileError}}, 1, nil},
+		{"compileOnly ignored", judge.Response{}, 0, judge.ErrNoDiagnostics},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+				response := tt.response
+				return &response, nil
+			})
+
+			diagnostics, err := judge.Diagnose(context.Background(), runner, "int main() {}", model.DefaultLanguage)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			errorCount := 0
+			for _, diagnostic := range diagnostics {
+				if diagnostic.Severity == model.SeverityError {
+					errorCount++
+				}
+			}
+			if errorCount != tt.errors {
+				t.Errorf("expected %d errors, got %d: %+v", tt.errors, errorCount, diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/fakejudge"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newFakeJudge starts a fake judge server that knows the addTwo problem
+func newFakeJudge(t *testing.T) *judge.HTTPRunner {
+	problems := []*model.Problem{{
+		Title:        "Add Two Numbers",
+		FunctionName: "addTwo",
+		Arguments:    []model.ParamType{{Name: "a", Type: model.TypeInt}, {Name: "b", Type: model.TypeInt}},
+		TestCases: []model.TestCase{
+			{Args: []interface{}{5, 3}, Expected: 8},
+			{Args: []interface{}{-5, 15}, Expected: 10},
+		},
+	}}
+	server := httptest.NewServer(fakejudge.NewServer(fakejudge.Script{Behavior: fakejudge.Echo}, problems))
+	t.Cleanup(server.Close)
+	return judge.NewHTTPRunner(server.URL)
+}
+
+func TestFakeJudgeEchoesExpectedOutputs(t *testing.T) {
+	runner := newFakeJudge(t)
+
+	response, err := runner.Run(context.Background(), &judge.Request{
+		Program:   "int addTwo(int a, int b) { return a + b; }",
+		FunName:   "addTwo",
+		TestCases: [][]interface{}{{5, 3}, {-5, 15}, {1, 1}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outputs := []float64{8, 10, 0}
+	for i, result := range response.Results {
+		if result.Output != outputs[i] || result.Error != "" {
+			t.Errorf("test case %d: unexpected result %+v", i, result)
+		}
+	}
+}
+
+func TestFakeJudgeCompileError(t *testing.T) {
+	runner := newFakeJudge(t)
+	program := "// fakejudge: compile-error 3:7 expected ';'\nint addTwo(int a, int b) { return a + b }"
+
+	response, err := runner.Run(context.Background(), &judge.Request{
+		Program:   program,
+		FunName:   "addTwo",
+		TestCases: [][]interface{}{{5, 3}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := response.Results[0]
+	if result.Line != 3 || result.Column != 7 || result.Error != "expected ';'" || result.Verdict() != model.VerdictCompileError {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	diagnostics, err := judge.Diagnose(context.Background(), runner, program, model.DefaultLanguage)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Line != 3 || diagnostics[0].Severity != model.SeverityError {
+		t.Errorf("unexpected diagnostics %+v", diagnostics)
+	}
+}
+
+func TestFakeJudgeRuntimeError(t *testing.T) {
+	runner := newFakeJudge(t)
+
+	response, err := runner.Run(context.Background(), &judge.Request{
+		Program:   "// fakejudge: runtime-error division by zero",
+		TestCases: [][]interface{}{{1, 0}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := response.Results[0]; result.Error != "division by zero" || result.Verdict() != model.VerdictRuntimeError {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestFakeJudgeStatus(t *testing.T) {
+	runner := newFakeJudge(t)
+
+	_, err := runner.Run(context.Background(), &judge.Request{Program: "// fakejudge: status 500"})
+	var statusErr *judge.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected a 500 status error, got %v", err)
+	}
+}
+
+func TestFakeJudgeMalformed(t *testing.T) {
+	runner := newFakeJudge(t)
+
+	_, err := runner.Run(context.Background(), &judge.Request{Program: "// fakejudge: malformed"})
+	var statusErr *judge.StatusError
+	if err == nil || errors.As(err, &statusErr) {
+		t.Fatalf("expected a decoding error, got %v", err)
+	}
+}
+
+func TestFakeJudgeDelay(t *testing.T) {
+	runner := newFakeJudge(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := runner.Run(ctx, &judge.Request{Program: "// fakejudge: delay 1s"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the delay to exceed the deadline, got %v", err)
+	}
+}
+
+func TestFakeJudgeRejectsUnknownDirective(t *testing.T) {
+	if _, err := fakejudge.ParseScript("// fakejudge: explode", fakejudge.Script{}); err == nil {
+		t.Fatal("expected an error for an unknown behavior")
+	}
+}
@@ -0,0 +1,184 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/judge"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingRunner answers every request and counts how often it was called
+func countingRunner(calls *atomic.Int64) judge.Runner {
+	return judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		calls.Add(1)
+		return &judge.Response{Results: []judge.Result{{Output: 1}}}, nil
+	})
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	var a, b atomic.Int64
+	pool := judge.NewPool(judge.RoundRobin, time.Second)
+	pool.AddNode("a", countingRunner(&a), judge.NewBreaker(5, time.Minute))
+	pool.AddNode("b", countingRunner(&b), judge.NewBreaker(5, time.Minute))
+
+	for i := 0; i < 10; i++ {
+		if _, err := pool.Run(context.Background(), &judge.Request{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if a.Load() != 5 || b.Load() != 5 {
+		t.Errorf("expected an even split, got a=%d b=%d", a.Load(), b.Load())
+	}
+}
+
+func TestPoolPrefersLeastOutstanding(t *testing.T) {
+	var fast atomic.Int64
+	release := make(chan struct{})
+	started := make(chan struct{})
+	slow := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		close(started)
+		<-release
+		return &judge.Response{}, nil
+	})
+
+	pool := judge.NewPool(judge.LeastOutstanding, time.Second)
+	pool.AddNode("slow", slow, judge.NewBreaker(5, time.Minute))
+	pool.AddNode("fast", countingRunner(&fast), judge.NewBreaker(5, time.Minute))
+
+	// Keep trying until the slow node has picked up a request
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-started:
+				return
+			default:
+				pool.Run(context.Background(), &judge.Request{})
+			}
+		}
+	}()
+	<-started
+
+	before := fast.Load()
+	for i := 0; i < 5; i++ {
+		pool.Run(context.Background(), &judge.Request{})
+	}
+	close(release)
+	<-done
+
+	if got := fast.Load() - before; got != 5 {
+		t.Errorf("expected the idle node to take all 5 requests, got %d", got)
+	}
+	if health := pool.Health(); len(health.Nodes) != 2 || !health.Healthy {
+		t.Errorf("unexpected pool health: %+v", health)
+	}
+}
+
+func TestPoolEjectsFailingNode(t *testing.T) {
+	var broken, healthy atomic.Int64
+	failing := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		broken.Add(1)
+		return nil, errors.New("connection refused")
+	})
+
+	pool := judge.NewPool(judge.RoundRobin, time.Second)
+	pool.AddNode("broken", failing, judge.NewBreaker(1, time.Minute))
+	pool.AddNode("healthy", countingRunner(&healthy), judge.NewBreaker(1, time.Minute))
+
+	for i := 0; i < 6; i++ {
+		if _, err := pool.Run(context.Background(), &judge.Request{}); err != nil {
+			t.Fatalf("expected failover to the healthy node, got %v", err)
+		}
+	}
+	if broken.Load() != 1 {
+		t.Errorf("expected the broken node to be ejected after one failure, got %d calls", broken.Load())
+	}
+	if healthy.Load() != 6 {
+		t.Errorf("expected the healthy node to answer every request, got %d", healthy.Load())
+	}
+
+	health := pool.Health()
+	if !health.Healthy {
+		t.Error("expected the pool to stay healthy while one node is up")
+	}
+	for _, node := range health.Nodes {
+		if node.URL == "broken" && (node.Healthy || node.Failures != 1) {
+			t.Errorf("unexpected metrics for the broken node: %+v", node)
+		}
+	}
+}
+
+func TestPoolFailsFastWhenAllNodesEjected(t *testing.T) {
+	failing := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	pool := judge.NewPool(judge.LeastOutstanding, time.Second)
+	pool.AddNode("a", failing, judge.NewBreaker(1, time.Minute))
+	pool.AddNode("b", failing, judge.NewBreaker(1, time.Minute))
+
+	if _, err := pool.Run(context.Background(), &judge.Request{}); err == nil {
+		t.Fatal("expected failure")
+	}
+
+	_, err := pool.Run(context.Background(), &judge.Request{})
+	var openErr *judge.CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.RetryAfter <= 0 {
+		t.Fatalf("expected an open circuit with Retry-After, got %v", err)
+	}
+	if pool.Health().Healthy {
+		t.Error("expected the pool to be reported unhealthy")
+	}
+}
+
+func TestPoolProbeClosesOnlyOnWellFormedAnswer(t *testing.T) {
+	var healthy atomic.Bool
+	runner := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		if !healthy.Load() {
+			return nil, &judge.StatusError{StatusCode: 400, Body: "no program"}
+		}
+		return &judge.Response{Results: []judge.Result{}}, nil
+	})
+
+	breaker := judge.NewBreaker(1, 10*time.Millisecond)
+	breaker.Failure()
+	pool := judge.NewPool(judge.RoundRobin, time.Second)
+	pool.AddNode("a", runner, breaker)
+	pool.HealthInterval = 5 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.StartHealthChecks(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+	if state := breaker.Snapshot().State; state == judge.BreakerClosed {
+		t.Fatal("expected a rejected probe to leave the breaker open")
+	}
+
+	healthy.Store(true)
+	deadline := time.Now().Add(time.Second)
+	for breaker.Snapshot().State != judge.BreakerClosed {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a well-formed probe answer to close the breaker, got %s", breaker.Snapshot().State)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPoolKeepsNodeOnCallerDeadline(t *testing.T) {
+	breaker := judge.NewBreaker(1, time.Minute)
+	pool := judge.NewPool(judge.RoundRobin, time.Second)
+	pool.AddNode("slow", &judge.FakeRunner{Delay: time.Second}, breaker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Run(ctx, &judge.Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if health := pool.Health(); !health.Healthy || health.Nodes[0].Failures != 0 {
+		t.Errorf("expected the node to stay in rotation, got %+v", health.Nodes[0])
+	}
+}
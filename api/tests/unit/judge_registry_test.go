@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegistryRoutesByLanguage(t *testing.T) {
+	var c, python atomic.Int64
+	registry := judge.NewRegistry()
+	registry.Register(model.DefaultLanguage, countingRunner(&c))
+	registry.Register("Python", countingRunner(&python))
+
+	for _, language := range []string{"", "c", "python", "PYTHON"} {
+		if _, err := registry.Run(context.Background(), &judge.Request{Language: language}); err != nil {
+			t.Fatalf("language %q: unexpected error: %v", language, err)
+		}
+	}
+	if c.Load() != 2 || python.Load() != 2 {
+		t.Errorf("expected 2 requests per language, got c=%d python=%d", c.Load(), python.Load())
+	}
+
+	_, err := registry.Run(context.Background(), &judge.Request{Language: "cobol"})
+	if !errors.Is(err, judge.ErrUnsupportedLanguage) {
+		t.Errorf("expected ErrUnsupportedLanguage, got %v", err)
+	}
+	if judge.SupportsLanguage(registry, "cobol") || !judge.SupportsLanguage(registry, "python") {
+		t.Error("unexpected language support")
+	}
+	if languages := registry.Languages(); len(languages) != 2 || languages[0] != "c" || languages[1] != "python" {
+		t.Errorf("unexpected languages %v", languages)
+	}
+}
+
+func TestRegistryHealthCoversEveryLanguage(t *testing.T) {
+	breaker := judge.NewBreaker(1, time.Minute)
+	breaker.Failure()
+
+	registry := judge.NewRegistry()
+	registry.Register(model.DefaultLanguage, judge.NewResilientRunner(judge.NewFakeRunner(), time.Second, 0, judge.NewBreaker(1, time.Minute)))
+	registry.Register("python", judge.NewResilientRunner(judge.NewFakeRunner(), time.Second, 0, breaker))
+
+	health := registry.Health()
+	if health.Healthy {
+		t.Error("expected the registry to be unhealthy while one language's judge is down")
+	}
+	if !health.Languages["c"].Healthy || health.Languages["python"].Healthy {
+		t.Errorf("unexpected per-language health: %+v", health.Languages)
+	}
+}
+
+func TestProblemAllowsLanguage(t *testing.T) {
+	legacy := &model.Problem{}
+	if !legacy.AllowsLanguage("") || !legacy.AllowsLanguage("C") || legacy.AllowsLanguage("python") {
+		t.Error("problems without languages should only accept the default language")
+	}
+
+	multi := &model.Problem{Languages: []string{"c", "Python"}}
+	if !multi.AllowsLanguage("python") || multi.AllowsLanguage("java") {
+		t.Error("unexpected languages accepted")
+	}
+}
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"testing"
+	"time"
+)
+
+func newLimitedProblem(timeLimitMs int, memoryLimitKB int64) *model.Problem {
+	return &model.Problem{
+		FunctionName:  "addTwo",
+		Arguments:     []model.ParamType{{Name: "a", Type: "int"}, {Name: "b", Type: "int"}},
+		TimeLimitMs:   timeLimitMs,
+		MemoryLimitKB: memoryLimitKB,
+		TestCases: []model.TestCase{
+			{Args: []interface{}{1, 2}, Expected: 3},
+			{Args: []interface{}{2, 2}, Expected: 4},
+		},
+	}
+}
+
+func TestBuildRequestForwardsLimits(t *testing.T) {
+	req, err := judge.BuildRequest(newLimitedProblem(500, 0), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.TimeLimitMs != 500 {
+		t.Errorf("expected the problem's time limit, got %d", req.TimeLimitMs)
+	}
+	if req.MemoryLimitKB != model.DefaultMemoryLimitKB {
+		t.Errorf("expected the default memory limit, got %d", req.MemoryLimitKB)
+	}
+}
+
+func TestRegistryLimitsApplyToUnlimitedProblems(t *testing.T) {
+	registry := judge.NewRegistry()
+	registry.SetLimits(judge.Limits{TimeLimitMs: 750, MemoryLimitKB: 1024})
+
+	problem := judge.LimitsOf(registry).Apply(newLimitedProblem(0, 4096))
+	if problem.TimeLimitMs != 750 {
+		t.Errorf("expected the registry's time limit, got %d", problem.TimeLimitMs)
+	}
+	if problem.MemoryLimitKB != 4096 {
+		t.Errorf("expected the problem's memory limit, got %d", problem.MemoryLimitKB)
+	}
+}
+
+func TestEvaluateDeadlineIsNotAVerdict(t *testing.T) {
+	registry := judge.NewRegistry()
+	registry.Register(model.DefaultLanguage, &judge.FakeRunner{Delay: time.Second})
+	registry.SetLimits(judge.Limits{CompileAllowance: 10 * time.Millisecond})
+
+	response, err := judge.Evaluate(context.Background(), registry, newLimitedProblem(10, 0), "", "")
+	if !errors.Is(err, judge.ErrDeadlineExceeded) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected ErrDeadlineExceeded, got %v (%+v)", err, response)
+	}
+}
+
+func TestJudgeReportedTimeLimit(t *testing.T) {
+	runner := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		results := make([]judge.Result, len(req.TestCases))
+		for i := range results {
+			results[i] = judge.Result{Error: "time limit exceeded", ErrorType: judge.ErrorTimeout}
+		}
+		return &judge.Response{Results: results}, nil
+	})
+
+	response, err := judge.Evaluate(context.Background(), runner, newLimitedProblem(10, 0), "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Verdict != model.VerdictTimeLimit {
+		t.Errorf("expected a time limit verdict, got %s", response.Verdict)
+	}
+}
+
+func TestResilientRunnerReleasesOnDeadline(t *testing.T) {
+	breaker := judge.NewBreaker(1, time.Minute)
+	rr := newTestResilientRunner(&judge.FakeRunner{Delay: time.Second}, 2, breaker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := rr.Run(ctx, &judge.Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if state := breaker.Snapshot().State; state != judge.BreakerClosed {
+		t.Errorf("expected the submission's deadline to leave the breaker closed, got %s", state)
+	}
+}
+
+func TestResilientRunnerCountsAttemptTimeoutAsFailure(t *testing.T) {
+	breaker := judge.NewBreaker(1, time.Minute)
+	rr := newTestResilientRunner(&judge.FakeRunner{Delay: time.Second}, 0, breaker)
+	rr.Timeout = 10 * time.Millisecond
+
+	if _, err := rr.Run(context.Background(), &judge.Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if state := breaker.Snapshot().State; state != judge.BreakerOpen {
+		t.Errorf("expected the breaker to open, got %s", state)
+	}
+}
+
+func TestResilientRunnerReleasesOnCancel(t *testing.T) {
+	breaker := judge.NewBreaker(1, time.Minute)
+	rr := newTestResilientRunner(&judge.FakeRunner{Delay: time.Second}, 2, breaker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := rr.Run(ctx, &judge.Request{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation, got %v", err)
+	}
+	if state := breaker.Snapshot().State; state != judge.BreakerClosed {
+		t.Errorf("expected the breaker to stay closed, got %s", state)
+	}
+}
+
+func TestGraderEnforcesReportedUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   judge.Result
+		expected model.Verdict
+	}{
+		{"within limits", judge.Result{Output: 3, TimeMs: 50, MemoryKB: 1024}, model.VerdictAccepted},
+		{"too slow", judge.Result{Output: 3, TimeMs: 150}, model.VerdictTimeLimit},
+		{"too much memory", judge.Result{Output: 3, MemoryKB: 4096}, model.VerdictMemoryLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grader := judge.NewGrader(newLimitedProblem(100, 2048))
+			if graded := grader.Add(0, tt.result); graded.Verdict != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, graded.Verdict)
+			}
+		})
+	}
+}
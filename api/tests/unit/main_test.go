@@ -0,0 +1,16 @@
+package unit
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Discard all standard logs during tests
+	log.SetOutput(io.Discard)
+	log.SetFlags(0)
+
+	os.Exit(m.Run())
+}
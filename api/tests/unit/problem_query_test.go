@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"errors"
+	model "learning_go/internal/models"
+	"testing"
+)
+
+func TestProblemQueryDefaults(t *testing.T) {
+	query := model.ProblemQuery{}
+	if err := query.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Sort != model.SortCreated || query.Limit != model.DefaultProblemLimit {
+		t.Errorf("expected to sort by creation with the default limit, got %+v", query)
+	}
+
+	search := model.ProblemQuery{Search: " add "}
+	if err := search.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if search.Sort != model.SortRelevance || search.Search != "add" {
+		t.Errorf("expected a trimmed search sorted by relevance, got %+v", search)
+	}
+}
+
+func TestProblemQueryValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query model.ProblemQuery
+	}{
+		{"unknown difficulty", model.ProblemQuery{Difficulties: []string{"trivial"}}},
+		{"unknown sort", model.ProblemQuery{Sort: "popularity"}},
+		{"relevance without search", model.ProblemQuery{Sort: model.SortRelevance}},
+		{"limit too large", model.ProblemQuery{Limit: model.MaxProblemLimit + 1}},
+		{"negative limit", model.ProblemQuery{Limit: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); !errors.Is(err, model.ErrInvalidQuery) {
+				t.Errorf("expected ErrInvalidQuery, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProblemCursor(t *testing.T) {
+	offset, err := model.DecodeCursor(model.EncodeCursor(40))
+	if err != nil || offset != 40 {
+		t.Fatalf("expected offset 40, got %d (%v)", offset, err)
+	}
+
+	for _, cursor := range []string{"not-a-cursor", model.EncodeCursor(-1)} {
+		if _, err := model.DecodeCursor(cursor); !errors.Is(err, model.ErrInvalidQuery) {
+			t.Errorf("cursor %q: expected ErrInvalidQuery, got %v", cursor, err)
+		}
+	}
+}
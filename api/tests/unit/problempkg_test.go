@@ -0,0 +1,137 @@
+package unit
+
+import (
+	model "learning_go/internal/models"
+	"learning_go/internal/problempkg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func packageProblem(t *testing.T) *model.Problem {
+	t.Helper()
+	problem := &model.Problem{
+		Slug:         "add-numbers",
+		Title:        "Add Numbers",
+		Description:  "Return the sum of `a` and `b`.\n",
+		Difficulty:   model.DifficultyEasy,
+		FunctionName: "add",
+		Arguments:    []model.ParamType{{Name: "a", Type: "int"}, {Name: "b", Type: "int"}},
+		ReturnType:   "int",
+		TimeLimitMs:  1000,
+		TestCases: []model.TestCase{
+			{Args: []interface{}{1, 2}, Expected: 3, Sample: true},
+			{Args: []interface{}{-1, 1}, Expected: 0, Weight: 2},
+		},
+		ReferenceSolution: "int add(int a, int b) { return a + b; }\n",
+		ReferenceLanguage: "c",
+	}
+	if err := problem.Validate(); err != nil {
+		t.Fatalf("invalid problem: %v", err)
+	}
+	return problem
+}
+
+func TestProblemPackageRoundTrip(t *testing.T) {
+	for _, name := range []string{"add-numbers", "add-numbers.zip"} {
+		t.Run(name, func(t *testing.T) {
+			problem := packageProblem(t)
+			dest := filepath.Join(t.TempDir(), name)
+			if err := problempkg.Write(dest, problem); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+
+			loaded, err := problempkg.Load(dest)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if err := loaded.Validate(); err != nil {
+				t.Fatalf("loaded package is invalid: %v", err)
+			}
+
+			differences, err := problempkg.Diff(problem, loaded)
+			if err != nil {
+				t.Fatalf("diff: %v", err)
+			}
+			if len(differences) > 0 {
+				t.Errorf("expected no differences, got %v", differences)
+			}
+			if !loaded.TestCases[0].Sample || loaded.TestCases[1].Weight != 2 {
+				t.Errorf("test options were not kept: %+v", loaded.TestCases)
+			}
+		})
+	}
+}
+
+func TestProblemPackageMissingOutput(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "add-numbers")
+	if err := problempkg.Write(dest, packageProblem(t)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// The reference solution generates outputs left out of the package
+	if err := os.Remove(filepath.Join(dest, "tests", "02.out")); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := problempkg.Load(dest)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := loaded.Validate(); err != nil {
+		t.Fatalf("loaded package is invalid: %v", err)
+	}
+	if loaded.TestCases[1].HasExpected() {
+		t.Errorf("expected test 02 to have no expected output, got %v", loaded.TestCases[1].Expected)
+	}
+}
+
+func TestProblemPackageDiff(t *testing.T) {
+	stored, pkg := packageProblem(t), packageProblem(t)
+	pkg.Difficulty = model.DifficultyMedium
+	pkg.TestCases[1].Expected = 1
+
+	differences, err := problempkg.Diff(stored, pkg)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	expected := []string{
+		`problem.json: difficulty: "easy" -> "medium"`,
+		"tests/02.out: 0 -> 1",
+	}
+	if len(differences) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, differences)
+	}
+	for i := range expected {
+		if differences[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], differences[i])
+		}
+	}
+}
+
+func TestProblemPackageUnknownField(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "add-numbers")
+	if err := problempkg.Write(dest, packageProblem(t)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	metadata := []byte(`{"slug": "add-numbers", "title": "Add Numbers", "dificulty": "easy"}`)
+	if err := os.WriteFile(filepath.Join(dest, problempkg.MetadataFile), metadata, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := problempkg.Load(dest); err == nil {
+		t.Error("expected a misspelled field to be rejected")
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Add Two Numbers":     "add-two-numbers",
+		"  FizzBuzz!  ":       "fizzbuzz",
+		"Sum of 1..N (Loops)": "sum-of-1-n-loops",
+	}
+	for title, expected := range tests {
+		if slug := model.Slugify(title); slug != expected {
+			t.Errorf("Slugify(%q): expected %q, got %q", title, expected, slug)
+		}
+	}
+}
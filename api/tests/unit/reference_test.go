@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"testing"
+)
+
+// addTwoRunner answers every test case with the sum of its two arguments,
+// standing in for a correct reference solution
+var addTwoRunner = &judge.FakeRunner{
+	Eval: func(funName string, args []interface{}) judge.Result {
+		return judge.Result{Output: args[0].(int) + args[1].(int)}
+	},
+}
+
+func newReferenceProblem(testCases ...model.TestCase) *model.Problem {
+	return &model.Problem{
+		FunctionName:      "addTwo",
+		Arguments:         []model.ParamType{{Name: "a", Type: "int"}, {Name: "b", Type: "int"}},
+		ReferenceSolution: "int addTwo(int a, int b) { return a + b; }",
+		TestCases:         testCases,
+	}
+}
+
+func TestCheckReferenceFlagsMismatches(t *testing.T) {
+	problem := newReferenceProblem(
+		model.TestCase{Args: []interface{}{1, 2}, Expected: 3},
+		model.TestCase{Args: []interface{}{2, 2}, Expected: 5},
+		model.TestCase{Args: []interface{}{4, 5}},
+	)
+
+	report, err := judge.CheckReference(context.Background(), addTwoRunner, problem)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Matched != 1 || report.Mismatched != 1 || report.Missing != 1 {
+		t.Fatalf("expected 1 match, 1 mismatch and 1 missing, got %+v", report)
+	}
+	if report.OK {
+		t.Error("expected the report not to be OK")
+	}
+	if status := report.Cases[1].Status; status != judge.ReferenceMismatch {
+		t.Errorf("expected test case 1 to mismatch, got %s", status)
+	}
+	if generated := report.Cases[1].Generated; generated != 4 {
+		t.Errorf("expected the generated output 4, got %v", generated)
+	}
+}
+
+func TestReferenceReportApply(t *testing.T) {
+	problem := newReferenceProblem(
+		model.TestCase{Args: []interface{}{2, 2}, Expected: 5},
+		model.TestCase{Args: []interface{}{4, 5}},
+	)
+
+	report, err := judge.CheckReference(context.Background(), addTwoRunner, problem)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if updated := report.Apply(problem, false); updated != 1 {
+		t.Fatalf("expected only the missing output to be filled, got %d", updated)
+	}
+	if problem.TestCases[0].Expected != 5 || problem.TestCases[1].Expected != 9 {
+		t.Fatalf("unexpected test cases after fill: %+v", problem.TestCases)
+	}
+
+	if updated := report.Apply(problem, true); updated != 2 {
+		t.Fatalf("expected both outputs to be written, got %d", updated)
+	}
+	if problem.TestCases[0].Expected != 4 {
+		t.Errorf("expected the mismatched output to be overwritten, got %v", problem.TestCases[0].Expected)
+	}
+}
+
+func TestCheckReferenceWithoutSolution(t *testing.T) {
+	problem := newReferenceProblem(model.TestCase{Args: []interface{}{1, 2}, Expected: 3})
+	problem.ReferenceSolution = ""
+
+	_, err := judge.CheckReference(context.Background(), addTwoRunner, problem)
+	if !errors.Is(err, judge.ErrNoReferenceSolution) {
+		t.Fatalf("expected ErrNoReferenceSolution, got %v", err)
+	}
+}
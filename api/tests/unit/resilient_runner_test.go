@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/judge"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// flakyRunner fails with the given errors, in order, then succeeds
+func flakyRunner(calls *int, failures ...error) judge.Runner {
+	return judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		*calls++
+		if *calls <= len(failures) {
+			return nil, failures[*calls-1]
+		}
+		return &judge.Response{Results: []judge.Result{{Output: 1}}}, nil
+	})
+}
+
+func newTestResilientRunner(runner judge.Runner, maxRetries int, breaker *judge.Breaker) *judge.ResilientRunner {
+	rr := judge.NewResilientRunner(runner, time.Second, maxRetries, breaker)
+	rr.Backoff = time.Millisecond
+	return rr
+}
+
+func TestResilientRunnerRetriesTransientFailures(t *testing.T) {
+	calls := 0
+	unavailable := &judge.StatusError{StatusCode: http.StatusServiceUnavailable}
+	rr := newTestResilientRunner(flakyRunner(&calls, unavailable, unavailable), 2, judge.NewBreaker(5, time.Minute))
+
+	if _, err := rr.Run(context.Background(), &judge.Request{}); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	if state := rr.Breaker.Snapshot().State; state != judge.BreakerClosed {
+		t.Errorf("expected closed breaker, got %s", state)
+	}
+}
+
+func TestResilientRunnerRetriesWithoutBackoff(t *testing.T) {
+	calls := 0
+	unavailable := &judge.StatusError{StatusCode: http.StatusServiceUnavailable}
+	rr := &judge.ResilientRunner{
+		Runner:     flakyRunner(&calls, unavailable, unavailable),
+		MaxRetries: 2,
+		Breaker:    judge.NewBreaker(5, time.Minute),
+	}
+
+	if _, err := rr.Run(context.Background(), &judge.Request{}); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestResilientRunnerDoesNotRetryRejectedRequests(t *testing.T) {
+	calls := 0
+	badRequest := &judge.StatusError{StatusCode: http.StatusBadRequest}
+	rr := newTestResilientRunner(flakyRunner(&calls, badRequest), 2, judge.NewBreaker(5, time.Minute))
+
+	_, err := rr.Run(context.Background(), &judge.Request{})
+	var statusErr *judge.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the 400 to be returned, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls)
+	}
+}
+
+func TestResilientRunnerOpensCircuit(t *testing.T) {
+	calls := 0
+	failing := judge.RunnerFunc(func(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+		calls++
+		return nil, errors.New("connection refused")
+	})
+	rr := newTestResilientRunner(failing, 0, judge.NewBreaker(2, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if _, err := rr.Run(context.Background(), &judge.Request{}); err == nil {
+			t.Fatal("expected failure")
+		}
+	}
+
+	_, err := rr.Run(context.Background(), &judge.Request{})
+	var openErr *judge.CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected the circuit to be open, got %v", err)
+	}
+	if openErr.RetryAfter <= 0 {
+		t.Errorf("expected a positive Retry-After, got %s", openErr.RetryAfter)
+	}
+	if calls != 2 {
+		t.Errorf("expected the open circuit to fail fast, got %d judge calls", calls)
+	}
+	if health := rr.Health(); health.Healthy {
+		t.Error("expected the judge to be reported unhealthy")
+	}
+}
+
+func TestBreakerClosesAfterSuccessfulTrial(t *testing.T) {
+	breaker := judge.NewBreaker(1, 10*time.Millisecond)
+	breaker.Failure()
+
+	if err := breaker.Allow(); err == nil {
+		t.Fatal("expected the breaker to reject calls while open")
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected a trial call after the cooldown, got %v", err)
+	}
+	if err := breaker.Allow(); err == nil {
+		t.Fatal("expected a single trial call while half-open")
+	}
+
+	breaker.Success()
+	if state := breaker.Snapshot().State; state != judge.BreakerClosed {
+		t.Errorf("expected closed breaker, got %s", state)
+	}
+}
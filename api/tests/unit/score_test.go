@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"testing"
+)
+
+func TestProblemScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		problem  model.Problem
+		passed   []bool
+		expected float64
+	}{
+		{
+			name:     "unweighted partial credit",
+			problem:  model.Problem{TestCases: make([]model.TestCase, 4)},
+			passed:   []bool{true, true, false, true},
+			expected: 75,
+		},
+		{
+			name: "weighted test cases",
+			problem: model.Problem{MaxScore: 10, TestCases: []model.TestCase{
+				{Weight: 1}, {Weight: 3},
+			}},
+			passed:   []bool{false, true},
+			expected: 7.5,
+		},
+		{
+			name: "subtask only scores when every case passes",
+			problem: model.Problem{MaxScore: 50, TestCases: []model.TestCase{
+				{Group: "small"}, {Group: "small"}, {Group: "large", Weight: 2}, {Group: "large", Weight: 2},
+			}},
+			passed:   []bool{true, true, true, false},
+			expected: 16.67,
+		},
+		{
+			name:     "fully solved",
+			problem:  model.Problem{MaxScore: 20, TestCases: []model.TestCase{{Group: "a"}, {}}},
+			passed:   []bool{true, true},
+			expected: 20,
+		},
+		{
+			name:     "no test cases",
+			problem:  model.Problem{},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.problem.Score(tt.passed); got != tt.expected {
+				t.Errorf("Score() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGraderReportsScore(t *testing.T) {
+	problem := &model.Problem{
+		FunctionName: "addTwo",
+		Arguments:    []model.ParamType{{Name: "a", Type: model.TypeInt}, {Name: "b", Type: model.TypeInt}},
+		MaxScore:     10,
+		TestCases: []model.TestCase{
+			{Args: []interface{}{1, 2}, Expected: 3, Weight: 4},
+			{Args: []interface{}{2, 2}, Expected: 4, Weight: 6},
+		},
+	}
+
+	response := judge.BuildResponse(problem, &judge.Response{Results: []judge.Result{
+		{Output: 3},
+		{Output: 5},
+	}})
+
+	if response.Score != 4 || response.MaxScore != 10 {
+		t.Errorf("expected 4/10, got %v/%v", response.Score, response.MaxScore)
+	}
+}
+
+func TestBestScores(t *testing.T) {
+	best := model.BestScores([]*model.UserSolution{
+		{ProblemID: "a", Score: 40},
+		{ProblemID: "a", Score: 90},
+		{ProblemID: "b", Score: 0},
+		{ProblemID: "a", Score: 60},
+	})
+	if best["a"] != 90 || best["b"] != 0 || len(best) != 2 {
+		t.Errorf("unexpected best scores %v", best)
+	}
+}
+
+func TestEarnedScoreOfLegacyResponse(t *testing.T) {
+	legacy := &model.CompileResponse{Result: []model.CompileResults{
+		{Status: "Success"}, {Status: "Failed"}, {Status: "Success"}, {Status: "Success"},
+	}}
+	if score := legacy.EarnedScore(); score != 75 {
+		t.Errorf("expected 75, got %v", score)
+	}
+}
@@ -0,0 +1,159 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"learning_go/internal/fakejudge"
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamJudgesOnce(t *testing.T) {
+	runner := judge.NewFakeRunner()
+	registry := judge.NewRegistry()
+	registry.Register(model.DefaultLanguage, runner)
+
+	req := &judge.Request{TestCases: [][]interface{}{{1}, {2}, {3}}}
+	var emitted []int
+	_, err := judge.Stream(context.Background(), registry, req, func(i int, result judge.Result) error {
+		emitted = append(emitted, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls := runner.Calls(); calls != 1 {
+		t.Errorf("expected the judge to be called once, got %d calls", calls)
+	}
+	if len(emitted) != 3 || emitted[0] != 0 || emitted[2] != 2 {
+		t.Errorf("expected every test case to be emitted in order, got %v", emitted)
+	}
+}
+
+func TestStreamEmitsResultsAsTheyAreJudged(t *testing.T) {
+	caseDelay := 100 * time.Millisecond
+	server := httptest.NewServer(fakejudge.NewServer(fakejudge.Script{Behavior: fakejudge.Echo, CaseDelay: caseDelay}, nil))
+	defer server.Close()
+
+	pool := judge.NewPool(judge.RoundRobin, time.Second)
+	pool.AddNode(server.URL, judge.NewHTTPRunner(server.URL), judge.NewBreaker(5, time.Minute))
+	runners := map[string]judge.Runner{
+		"http":      judge.NewHTTPRunner(server.URL),
+		"resilient": judge.NewResilientRunner(judge.NewHTTPRunner(server.URL), time.Second, 2, judge.NewBreaker(5, time.Minute)),
+		"pool":      pool,
+	}
+	for name, runner := range runners {
+		t.Run(name, func(t *testing.T) {
+			req := &judge.Request{FunName: "f", TestCases: [][]interface{}{{1}, {2}, {3}}}
+			start := time.Now()
+			var firstAt time.Duration
+			response, err := judge.Stream(context.Background(), runner, req, func(i int, result judge.Result) error {
+				if i == 0 {
+					firstAt = time.Since(start)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(response.Results) != 3 {
+				t.Fatalf("expected 3 results, got %+v", response)
+			}
+			if total := time.Since(start); firstAt > total-caseDelay {
+				t.Errorf("expected the first result before the last test case was judged, got it after %s of %s", firstAt, total)
+			}
+		})
+	}
+}
+
+func TestStreamKeepsDiagnostics(t *testing.T) {
+	runner := newFakeJudge(t)
+	response, err := judge.Stream(context.Background(), runner, &judge.Request{
+		Program:   "// fakejudge: compile-error 2:4 expected ';'",
+		FunName:   "addTwo",
+		TestCases: [][]interface{}{{5, 3}},
+	}, func(int, judge.Result) error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(response.Diagnostics) != 1 || response.Diagnostics[0].Line != 2 {
+		t.Errorf("expected the streamed diagnostics, got %+v", response.Diagnostics)
+	}
+}
+
+func TestStreamFromJudgeThatDoesNotStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results": [{"output": 1}, {"output": 2}], "diagnostics": []}`))
+	}))
+	defer server.Close()
+
+	var outputs []interface{}
+	response, err := judge.Stream(context.Background(), judge.NewHTTPRunner(server.URL), &judge.Request{TestCases: [][]interface{}{{1}, {2}}},
+		func(i int, result judge.Result) error {
+			outputs = append(outputs, result.Output)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outputs) != 2 || outputs[1] != 2.0 || response.Diagnostics == nil {
+		t.Errorf("unexpected results %v of %+v", outputs, response)
+	}
+}
+
+// brokenStreamer emits the first result, then loses the judge
+type brokenStreamer struct {
+	calls int
+}
+
+func (b *brokenStreamer) Run(ctx context.Context, req *judge.Request) (*judge.Response, error) {
+	return nil, errors.New("not called")
+}
+
+func (b *brokenStreamer) Stream(ctx context.Context, req *judge.Request, emit func(int, judge.Result) error) (*judge.Response, error) {
+	b.calls++
+	if err := emit(0, judge.Result{Output: 1}); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("connection reset")
+}
+
+func TestStreamIsNotRetriedAfterEmitting(t *testing.T) {
+	streamer := &brokenStreamer{}
+	rr := judge.NewResilientRunner(streamer, time.Second, 3, judge.NewBreaker(5, time.Minute))
+	rr.Backoff = time.Millisecond
+
+	emitted := 0
+	_, err := judge.Stream(context.Background(), rr, &judge.Request{TestCases: [][]interface{}{{1}, {2}}}, func(int, judge.Result) error {
+		emitted++
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected the judge failure")
+	}
+	if streamer.calls != 1 || emitted != 1 {
+		t.Errorf("expected a single attempt, got %d attempts and %d results", streamer.calls, emitted)
+	}
+}
+
+func TestStreamEmitErrorKeepsBreakerClosed(t *testing.T) {
+	breaker := judge.NewBreaker(1, time.Minute)
+	rr := judge.NewResilientRunner(judge.NewFakeRunner(), time.Second, 0, breaker)
+
+	clientGone := errors.New("client gone")
+	_, err := judge.Stream(context.Background(), rr, &judge.Request{TestCases: [][]interface{}{{1}}}, func(int, judge.Result) error {
+		return clientGone
+	})
+	if !errors.Is(err, clientGone) {
+		t.Fatalf("expected the emit error, got %v", err)
+	}
+	if state := breaker.Snapshot().State; state != judge.BreakerClosed {
+		t.Errorf("expected the breaker to stay closed, got %s", state)
+	}
+}
@@ -0,0 +1,46 @@
+package unit
+
+import (
+	model "learning_go/internal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSubmissionSolution(t *testing.T) {
+	createdAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	finishedAt := createdAt.Add(1500 * time.Millisecond)
+	problemID := primitive.NewObjectID()
+
+	done := &model.Submission{
+		ID:         primitive.NewObjectID(),
+		Username:   "alice",
+		ProblemID:  problemID,
+		Code:       "int f() { return 0; }",
+		State:      model.SubmissionDone,
+		Verdict:    model.VerdictWrongAnswer,
+		Score:      50,
+		CreatedAt:  createdAt,
+		FinishedAt: &finishedAt,
+		Result: &model.CompileResponse{Result: []model.CompileResults{
+			{Status: "Success"}, {Status: "Failed"},
+		}},
+	}
+
+	solution := done.Solution()
+	if solution.Status != "partial" || solution.Verdict != model.VerdictWrongAnswer || solution.Score != 50 {
+		t.Errorf("unexpected solution summary: %+v", solution)
+	}
+	if solution.ProblemID != problemID.Hex() || solution.UserID != "alice" || solution.Language != model.DefaultLanguage {
+		t.Errorf("unexpected solution identity: %+v", solution)
+	}
+	if solution.ExecutionTime != "1.5s" || solution.SubmittedAt != "2025-06-01T12:00:00Z" {
+		t.Errorf("unexpected solution timings: %+v", solution)
+	}
+
+	failed := &model.Submission{State: model.SubmissionFailed, CreatedAt: createdAt}
+	if solution := failed.Solution(); solution.Status != "failed" || solution.Verdict != model.VerdictInternalError {
+		t.Errorf("unexpected failed solution: %+v", solution)
+	}
+}
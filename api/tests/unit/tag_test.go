@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"errors"
+	model "learning_go/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags := model.NormalizeTags([]string{" Loops", "math", "loops", ""})
+	if expected := []string{"loops", "math"}; !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+}
+
+func TestTagValidate(t *testing.T) {
+	tests := []struct {
+		tag   model.Tag
+		valid bool
+	}{
+		{model.Tag{Name: "recursion", Week: 4}, true},
+		{model.Tag{Name: " Dynamic-Programming "}, true},
+		{model.Tag{Name: "two words"}, false},
+		{model.Tag{Name: "trailing-"}, false},
+		{model.Tag{Name: ""}, false},
+		{model.Tag{Name: "loops", Week: -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag.Name, func(t *testing.T) {
+			err := tt.tag.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, model.ErrInvalidTag) {
+				t.Errorf("expected ErrInvalidTag, got %v", err)
+			}
+		})
+	}
+}
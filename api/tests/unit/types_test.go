@@ -0,0 +1,214 @@
+package unit
+
+import (
+	"encoding/json"
+	model "learning_go/internal/models"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		value    interface{}
+		expected interface{}
+		error    string
+	}{
+		{"int", model.TypeInt, 5, 5, ""},
+		{"int from int32", model.TypeInt, int32(-5), -5, ""},
+		{"int from int64", model.TypeInt, int64(7), 7, ""},
+		{"int from JSON number", model.TypeInt, float64(42), 42, ""},
+		{"int from json.Number", model.TypeInt, json.Number("12"), 12, ""},
+		{"fractional int", model.TypeInt, 1.5, nil, "expected int"},
+		{"int overflow", model.TypeInt, 1e19, nil, "out of range"},
+		{"int underflow", model.TypeInt, -1e19, nil, "out of range"},
+		{"infinite int", model.TypeInt, math.Inf(1), nil, "out of range"},
+		{"int from string", model.TypeInt, "5", nil, "expected int, got string"},
+		{"float", model.TypeFloat, 2.5, 2.5, ""},
+		{"float from int", model.TypeFloat, 2, 2.0, ""},
+		{"float from int32", model.TypeFloat, int32(2), 2.0, ""},
+		{"float from bool", model.TypeFloat, true, nil, "expected float"},
+		{"string", model.TypeString, "hi", "hi", ""},
+		{"string from int", model.TypeString, 1, nil, "expected string"},
+		{"bool", model.TypeBool, true, true, ""},
+		{"bool from string", model.TypeBool, "true", nil, "expected bool"},
+		{"int array", model.TypeIntArray, []interface{}{float64(1), 2}, []interface{}{1, 2}, ""},
+		{"int array from BSON", model.TypeIntArray, primitive.A{int32(3)}, []interface{}{3}, ""},
+		{"empty array", model.TypeStringArray, []interface{}{}, []interface{}{}, ""},
+		{"float array", model.TypeFloatArray, []interface{}{1, 0.5}, []interface{}{1.0, 0.5}, ""},
+		{"bool array", model.TypeBoolArray, []interface{}{false}, []interface{}{false}, ""},
+		{"bad array element", model.TypeIntArray, []interface{}{1, "x"}, nil, "element 1"},
+		{"array from scalar", model.TypeIntArray, 1, nil, "expected int[]"},
+		{"unknown type", "char", "c", nil, "unknown type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := model.ConvertValue(tt.typ, tt.value)
+			if tt.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("expected an error containing %q, got %v (%v)", tt.error, err, value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		input    string
+		expected interface{}
+		error    string
+	}{
+		{"int", model.TypeInt, "-8", -8, ""},
+		{"bad int", model.TypeInt, "eight", nil, "expected int"},
+		{"int overflow", model.TypeInt, "99999999999999999999", nil, "expected int"},
+		{"float", model.TypeFloat, "0.25", 0.25, ""},
+		{"bad float", model.TypeFloat, "1,5", nil, "expected float"},
+		{"string", model.TypeString, "hello", "hello", ""},
+		{"bool", model.TypeBool, "false", false, ""},
+		{"bad bool", model.TypeBool, "yes", nil, "expected bool"},
+		{"int array", model.TypeIntArray, "1, 2,3", []interface{}{1, 2, 3}, ""},
+		{"empty array", model.TypeIntArray, " ", []interface{}{}, ""},
+		{"bad array element", model.TypeFloatArray, "1,x", nil, "expected float"},
+		{"unknown type", "char", "c", nil, "unknown type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := model.ParseValue(tt.typ, tt.input)
+			if tt.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("expected an error containing %q, got %v (%v)", tt.error, err, value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func newTypedProblem(testCases ...model.TestCase) *model.Problem {
+	return &model.Problem{
+		FunctionName: "sumAll",
+		Arguments:    []model.ParamType{{Name: "xs", Type: model.TypeIntArray}, {Name: "scale", Type: model.TypeFloat}},
+		ReturnType:   model.TypeFloat,
+		TestCases:    testCases,
+	}
+}
+
+func TestTestCaseArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		testCase model.TestCase
+		expected []interface{}
+		error    string
+	}{
+		{"typed", model.TestCase{Args: []interface{}{[]interface{}{float64(1), float64(2)}, float64(2)}},
+			[]interface{}{[]interface{}{1, 2}, 2.0}, ""},
+		{"legacy input", model.TestCase{Input: "1,2 0.5"}, []interface{}{[]interface{}{1, 2}, 0.5}, ""},
+		{"too few args", model.TestCase{Args: []interface{}{[]interface{}{}}}, nil, "expected 2 arguments, got 1"},
+		{"too many inputs", model.TestCase{Input: "1 2 3"}, nil, "expected 2 arguments, got 3"},
+		{"wrong type", model.TestCase{Args: []interface{}{[]interface{}{1}, "x"}}, nil, `argument "scale"`},
+		{"int overflow", model.TestCase{Args: []interface{}{[]interface{}{1e300}, 1}}, nil, "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := newTypedProblem(tt.testCase).TestCaseArgs(0)
+			if tt.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("expected an error containing %q, got %v (%v)", tt.error, err, args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(args, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, args)
+			}
+		})
+	}
+}
+
+func TestTestCaseArgsWithoutDeclaredArguments(t *testing.T) {
+	problem := &model.Problem{TestCases: []model.TestCase{{Input: "5 abc"}}}
+	args, err := problem.TestCaseArgs(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []interface{}{5, "abc"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %#v, got %#v", expected, args)
+	}
+}
+
+func TestExpectedOutput(t *testing.T) {
+	problem := newTypedProblem(
+		model.TestCase{Expected: 3},
+		model.TestCase{Output: "1.5"},
+		model.TestCase{Output: "one"},
+	)
+
+	for i, expected := range []interface{}{3.0, 1.5} {
+		value, err := problem.ExpectedOutput(i)
+		if err != nil {
+			t.Fatalf("test case %d: unexpected error: %v", i, err)
+		}
+		if value != expected {
+			t.Errorf("test case %d: expected %v, got %v", i, expected, value)
+		}
+	}
+	if _, err := problem.ExpectedOutput(2); err == nil || !strings.Contains(err.Error(), "expected float") {
+		t.Errorf("expected a float parse error, got %v", err)
+	}
+}
+
+func TestNormalizeTestCases(t *testing.T) {
+	problem := newTypedProblem(
+		model.TestCase{Input: "1,2 2", Output: "6", Sample: true},
+		model.TestCase{Args: []interface{}{[]interface{}{4}, 1}, Expected: 4},
+	)
+	if err := problem.NormalizeTestCases(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := problem.TestCases[0]
+	if first.Input != "" || first.Output != "" || !first.Sample {
+		t.Errorf("expected the legacy fields to be rewritten and the sample flag kept, got %+v", first)
+	}
+	if !reflect.DeepEqual(first.Args, []interface{}{[]interface{}{1, 2}, 2.0}) || first.Expected != 6.0 {
+		t.Errorf("unexpected normalized test case %+v", first)
+	}
+
+	errorCases := map[string]*model.Problem{
+		"unknown argument type": {Arguments: []model.ParamType{{Name: "c", Type: "char"}}},
+		"unknown return type":   {ReturnType: "void"},
+		"bad test case":         newTypedProblem(model.TestCase{Input: "1 2 3", Output: "1"}),
+		"missing output":        newTypedProblem(model.TestCase{Input: "1 2"}),
+	}
+	for name, problem := range errorCases {
+		if err := problem.NormalizeTestCases(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"errors"
+	model "learning_go/internal/models"
+	"strings"
+	"testing"
+)
+
+func newAuthoredProblem() *model.Problem {
+	return &model.Problem{
+		Title:        "Add Two Numbers",
+		Difficulty:   model.DifficultyEasy,
+		FunctionName: "addTwo",
+		Arguments:    []model.ParamType{{Name: "a", Type: model.TypeInt}, {Name: "b", Type: model.TypeInt}},
+		TestCases: []model.TestCase{
+			{Args: []interface{}{1, 2}, Expected: 3, Sample: true},
+			{Input: "5 3", Output: "8"},
+		},
+	}
+}
+
+func TestProblemValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *model.Problem)
+		error  string
+	}{
+		{"valid problem", func(p *model.Problem) {}, ""},
+		{"missing title", func(p *model.Problem) { p.Title = " " }, "title is required"},
+		{"unknown difficulty", func(p *model.Problem) { p.Difficulty = "Easy" }, "difficulty must be"},
+		{"function name with spaces", func(p *model.Problem) { p.FunctionName = "add two" }, "not a valid identifier"},
+		{"function name starting with a digit", func(p *model.Problem) { p.FunctionName = "2add" }, "not a valid identifier"},
+		{"duplicate argument", func(p *model.Problem) { p.Arguments[1].Name = "a" }, "declared twice"},
+		{"unknown argument type", func(p *model.Problem) { p.Arguments[0].Type = "long" }, "unknown type"},
+		{"wrong arity", func(p *model.Problem) { p.TestCases[0].Args = []interface{}{1} }, "expected 2 arguments"},
+		{"no test cases", func(p *model.Problem) { p.TestCases = nil }, "at least one test case"},
+		{"inputs without arguments", func(p *model.Problem) { p.Arguments = nil }, "expected 0 arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := newAuthoredProblem()
+			tt.modify(problem)
+
+			err := problem.Validate()
+			if tt.error == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, model.ErrInvalidProblem) {
+				t.Fatalf("expected ErrInvalidProblem, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %q", tt.error, err.Error())
+			}
+		})
+	}
+}
+
+func TestProblemValidateNormalizesTestCases(t *testing.T) {
+	problem := newAuthoredProblem()
+	if err := problem.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	legacy := problem.TestCases[1]
+	if legacy.Input != "" || len(legacy.Args) != 2 || legacy.Expected != 8 {
+		t.Errorf("expected the legacy test case to be typed, got %+v", legacy)
+	}
+}
+
+func TestProblemPatchReplacesTestCases(t *testing.T) {
+	problem := newAuthoredProblem()
+	if err := problem.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	patched, err := problem.Patch([]byte(`{"test_cases": [{"input": "2 2", "output": "4"}, {"args": [1, 1], "expected": 2}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := patched.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if patched.Title != problem.Title || patched.FunctionName != problem.FunctionName {
+		t.Errorf("expected fields left out of the patch to be kept, got %+v", patched)
+	}
+	expected := []interface{}{4, 2}
+	for i, testCase := range patched.TestCases {
+		if testCase.Sample {
+			t.Errorf("test case %d: expected the sample flag not to be kept", i)
+		}
+		if testCase.Expected != expected[i] {
+			t.Errorf("test case %d: expected %v, got %v", i, expected[i], testCase.Expected)
+		}
+	}
+}
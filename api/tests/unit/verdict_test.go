@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"learning_go/internal/judge"
+	model "learning_go/internal/models"
+	"testing"
+)
+
+func TestVerdictWorst(t *testing.T) {
+	tests := []struct {
+		a, b     model.Verdict
+		expected model.Verdict
+	}{
+		{"", model.VerdictAccepted, model.VerdictAccepted},
+		{model.VerdictWrongAnswer, "", model.VerdictWrongAnswer},
+		{model.VerdictAccepted, model.VerdictWrongAnswer, model.VerdictWrongAnswer},
+		{model.VerdictWrongAnswer, model.VerdictRuntimeError, model.VerdictRuntimeError},
+		{model.VerdictRuntimeError, model.VerdictMemoryLimit, model.VerdictMemoryLimit},
+		{model.VerdictMemoryLimit, model.VerdictTimeLimit, model.VerdictTimeLimit},
+		{model.VerdictTimeLimit, model.VerdictInternalError, model.VerdictInternalError},
+		{model.VerdictInternalError, model.VerdictCompileError, model.VerdictCompileError},
+		{model.VerdictCompileError, model.VerdictAccepted, model.VerdictCompileError},
+	}
+
+	for _, tt := range tests {
+		if worst := tt.a.Worst(tt.b); worst != tt.expected {
+			t.Errorf("%q.Worst(%q): expected %q, got %q", tt.a, tt.b, tt.expected, worst)
+		}
+		if worst := tt.b.Worst(tt.a); worst != tt.expected {
+			t.Errorf("%q.Worst(%q): expected %q, got %q", tt.b, tt.a, tt.expected, worst)
+		}
+	}
+}
+
+func TestOverallVerdict(t *testing.T) {
+	failed := []model.CompileResults{{Status: "Success"}, {Status: "Failed"}}
+	passed := []model.CompileResults{{Status: "Success"}}
+	tests := []struct {
+		name     string
+		response model.CompileResponse
+		expected model.Verdict
+	}{
+		{"stored verdict", model.CompileResponse{Verdict: model.VerdictTimeLimit, Result: passed}, model.VerdictTimeLimit},
+		{"all passed", model.CompileResponse{Result: passed}, model.VerdictAccepted},
+		{"failed test case", model.CompileResponse{Result: failed}, model.VerdictWrongAnswer},
+		{"error without results", model.CompileResponse{Error: "judge down"}, model.VerdictInternalError},
+		{"error at a line", model.CompileResponse{Error: "expected ';'", Line: 3, Result: failed}, model.VerdictCompileError},
+		{"error at a column", model.CompileResponse{Error: "expected ';'", Column: 7, Result: failed}, model.VerdictCompileError},
+		{"error without position", model.CompileResponse{Error: "segfault", Result: failed}, model.VerdictRuntimeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if verdict := tt.response.OverallVerdict(); verdict != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, verdict)
+			}
+		})
+	}
+}
+
+func TestResultVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   judge.Result
+		expected model.Verdict
+	}{
+		{"compile error type", judge.Result{Error: "x", ErrorType: judge.ErrorCompile}, model.VerdictCompileError},
+		{"runtime error type", judge.Result{Error: "x", ErrorType: judge.ErrorRuntime, Line: 2}, model.VerdictRuntimeError},
+		{"timeout type", judge.Result{Error: "x", ErrorType: judge.ErrorTimeout}, model.VerdictTimeLimit},
+		{"memory type", judge.Result{Error: "x", ErrorType: judge.ErrorMemory}, model.VerdictMemoryLimit},
+		{"untyped with a line", judge.Result{Error: "x", Line: 4}, model.VerdictCompileError},
+		{"untyped with a column", judge.Result{Error: "x", Column: 1}, model.VerdictCompileError},
+		{"untyped without position", judge.Result{Error: "x"}, model.VerdictRuntimeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if verdict := tt.result.Verdict(); verdict != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, verdict)
+			}
+		})
+	}
+}